@@ -0,0 +1,212 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 精度评估选项
+type AccuracyOptions struct {
+	Percentiles []float64 // 需要统计的绝对误差百分位，取值 0-100
+	ErrorRaster bool      // 是否输出误差栅格 (TIN - DEM)
+}
+
+var DefaultAccuracyPercentiles = []float64{50, 90, 95, 99}
+
+// TIN 相对源栅格的精度报告，误差均为 TIN 高程减去 DEM 高程
+type AccuracyReport struct {
+	Count        int // 参与统计的有效单元数
+	MaxAbsError  float64
+	MaxErrorRow  int
+	MaxErrorCol  int
+	MeanError    float64 // 有符号平均误差，反映整体偏高或偏低
+	MeanAbsError float64
+	RMSE         float64
+	Percentiles  map[float64]float64
+	ErrorRaster  *RasterDouble
+}
+
+func (r *AccuracyReport) String() string {
+	return fmt.Sprintf("count=%d max=%.4f rmse=%.4f mean=%.4f meanAbs=%.4f",
+		r.Count, r.MaxAbsError, r.RMSE, r.MeanError, r.MeanAbsError)
+}
+
+// 误差累加器，每个栅格单元只统计一次
+type accuracyAccumulator struct {
+	report  *AccuracyReport
+	visited *RasterChar
+	errors  []float64
+	sum     float64
+	sumAbs  float64
+	sumSq   float64
+}
+
+func newAccuracyAccumulator(rows, cols int, opts *AccuracyOptions) *accuracyAccumulator {
+	acc := &accuracyAccumulator{
+		report:  &AccuracyReport{MaxErrorRow: -1, MaxErrorCol: -1},
+		visited: NewRasterChar(rows, cols, 0),
+	}
+	if opts.ErrorRaster {
+		acc.report.ErrorRaster = NewRasterDouble(rows, cols, math.NaN())
+	}
+	return acc
+}
+
+func (a *accuracyAccumulator) add(row, col int, tinZ, demZ float64) {
+	if a.visited.Value(row, col) != 0 {
+		return
+	}
+	a.visited.SetValue(row, col, 1)
+
+	diff := tinZ - demZ
+	abs := math.Abs(diff)
+	a.sum += diff
+	a.sumAbs += abs
+	a.sumSq += diff * diff
+	a.errors = append(a.errors, abs)
+
+	if abs > a.report.MaxAbsError || a.report.Count == 0 {
+		a.report.MaxAbsError = abs
+		a.report.MaxErrorRow = row
+		a.report.MaxErrorCol = col
+	}
+	a.report.Count++
+
+	if a.report.ErrorRaster != nil {
+		a.report.ErrorRaster.SetValue(row, col, diff)
+	}
+}
+
+func (a *accuracyAccumulator) finish(opts *AccuracyOptions) *AccuracyReport {
+	r := a.report
+	r.Percentiles = make(map[float64]float64)
+	if r.Count == 0 {
+		return r
+	}
+
+	n := float64(r.Count)
+	r.MeanError = a.sum / n
+	r.MeanAbsError = a.sumAbs / n
+	r.RMSE = math.Sqrt(a.sumSq / n)
+
+	sort.Float64s(a.errors)
+	for _, p := range opts.Percentiles {
+		r.Percentiles[p] = percentileSorted(a.errors, p)
+	}
+	return r
+}
+
+// 已排序数组的百分位数，相邻秩之间线性插值
+func percentileSorted(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	if p <= 0 {
+		return sorted[0]
+	}
+	if p >= 100 {
+		return sorted[len(sorted)-1]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	frac := rank - float64(lo)
+	return sorted[lo]*(1-frac) + sorted[hi]*frac
+}
+
+// 按扫描线遍历三角形覆盖的栅格单元中心
+// p 为像素空间顶点 (列, 行, 高程)，fn 收到单元行列号及三角形平面在该单元中心的插值高程
+// 位于公共边上的单元会被相邻三角形各访问一次
+func scanTriangleCells(p [3][3]float64, rows, cols int, fn func(row, col int, z float64)) {
+	if Orientation([2]float64{p[0][0], p[0][1]}, [2]float64{p[1][0], p[1][1]}, [2]float64{p[2][0], p[2][1]}) == 0 {
+		return
+	}
+	plane := NewPlane(p[0], p[1], p[2])
+
+	byy := [3][2]float64{{p[0][0], p[0][1]}, {p[1][0], p[1][1]}, {p[2][0], p[2][1]}}
+	orderTrianglePoints(&byy)
+
+	edgeX := func(a, b [2]float64, y float64) float64 {
+		if b[1] == a[1] {
+			return a[0]
+		}
+		return a[0] + (b[0]-a[0])*(y-a[1])/(b[1]-a[1])
+	}
+
+	starty := int(math.Max(0, math.Ceil(byy[0][1])))
+	endy := int(math.Min(float64(rows-1), math.Floor(byy[2][1])))
+
+	for y := starty; y <= endy; y++ {
+		fy := float64(y)
+		x1 := edgeX(byy[0], byy[2], fy)
+		var x2 float64
+		if fy < byy[1][1] {
+			x2 = edgeX(byy[0], byy[1], fy)
+		} else {
+			x2 = edgeX(byy[1], byy[2], fy)
+		}
+
+		startx := int(math.Max(0, math.Ceil(math.Min(x1, x2)-EPS)))
+		endx := int(math.Min(float64(cols-1), math.Floor(math.Max(x1, x2)+EPS)))
+		for x := startx; x <= endx; x++ {
+			fn(y, x, plane.Eval(float64(x), fy))
+		}
+	}
+}
+
+// 将 Mesh 与源栅格逐单元比较，Mesh 顶点须与栅格处于同一坐标系
+// (即未经过 Raster.SetTransform 变换)
+func EvaluateMesh(mesh *Mesh, raster *RasterDouble, opts *AccuracyOptions) (*AccuracyReport, error) {
+	if mesh == nil || raster == nil {
+		return nil, fmt.Errorf("nil mesh or raster")
+	}
+	if raster.CellSize() <= 0 {
+		return nil, fmt.Errorf("invalid cellsize: %.6f", raster.CellSize())
+	}
+	if opts == nil {
+		opts = &AccuracyOptions{Percentiles: DefaultAccuracyPercentiles}
+	}
+
+	rows, cols := raster.Rows(), raster.Cols()
+	noDataValue := raster.NoData
+	acc := newAccuracyAccumulator(rows, cols, opts)
+	if acc.report.ErrorRaster != nil {
+		acc.report.ErrorRaster.copyGeoReference(&raster.RasterGrid)
+	}
+
+	mesh.GenerateTriangles()
+	for _, t := range mesh.Triangles {
+		var p [3][3]float64
+		for i := range t {
+			p[i][0], p[i][1] = raster.geoToPixel(t[i][0], t[i][1])
+			p[i][2] = t[i][2]
+		}
+		scanTriangleCells(p, rows, cols, func(row, col int, z float64) {
+			v := raster.Value(row, col)
+			if !isNoData(v, noDataValue) && !math.IsNaN(z) {
+				acc.add(row, col, z, v)
+			}
+		})
+	}
+
+	return acc.finish(opts), nil
+}
+
+// 使用最终的三角剖分与源栅格 (含高程基准转换) 比较
+func (z *ZemlyaMesh) Evaluate(opts *AccuracyOptions) (*AccuracyReport, error) {
+	if z.elevation() == nil || z.Result == nil || z.firstFace == nil {
+		return nil, fmt.Errorf("mesh has not been generated")
+	}
+	if opts == nil {
+		opts = &AccuracyOptions{Percentiles: DefaultAccuracyPercentiles}
+	}
+
+	acc := newAccuracyAccumulator(z.grid().Rows(), z.grid().Cols(), opts)
+	if acc.report.ErrorRaster != nil {
+		acc.report.ErrorRaster.copyGeoReference(z.grid())
+	}
+	z.scanResultCells(acc.add)
+
+	return acc.finish(opts), nil
+}
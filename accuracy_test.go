@@ -0,0 +1,117 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+// 两个三角形覆盖整个栅格的平面网格
+func createPlaneMesh(r *RasterDouble, fn func(x, y float64) float64) *Mesh {
+	x0, x1 := r.ColToX(0), r.ColToX(r.Cols()-1)
+	y0, y1 := r.RowToY(r.Rows()-1), r.RowToY(0)
+	a := Vertex{x0, y0, fn(x0, y0)}
+	b := Vertex{x1, y0, fn(x1, y0)}
+	c := Vertex{x1, y1, fn(x1, y1)}
+	d := Vertex{x0, y1, fn(x0, y1)}
+
+	m := &Mesh{}
+	m.InitFromTriangles([]Triangle{{a, b, c}, {a, c, d}})
+	m.GenerateDecomposed()
+	return m
+}
+
+func TestEvaluateMesh(t *testing.T) {
+	plane := func(x, y float64) float64 { return 0.5*x - 0.25*y + 3 }
+
+	raster := NewRasterDouble(9, 9, -9999)
+	raster.SetXYPos(0, 0, 1.0)
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			raster.SetValue(row, col, plane(raster.ColToX(col), raster.RowToY(row)))
+		}
+	}
+
+	t.Run("ExactPlane", func(t *testing.T) {
+		report, err := EvaluateMesh(createPlaneMesh(raster, plane), raster, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Count != 81 {
+			t.Errorf("有效单元数错误: 预期 81, 实际 %d", report.Count)
+		}
+		if report.MaxAbsError > 1e-9 || report.RMSE > 1e-9 {
+			t.Errorf("平面网格应无误差: %v", report)
+		}
+	})
+
+	t.Run("OffsetPlane", func(t *testing.T) {
+		// 整体抬高 1 米，并放入一个无效值和一个异常值
+		src := NewRasterDouble(9, 9, -9999)
+		src.copyGeoReference(&raster.RasterGrid)
+		copy(src.DataSlice(), raster.DataSlice())
+		src.SetValue(0, 0, -9999)
+		src.SetValue(4, 4, src.Value(4, 4)-3)
+
+		mesh := createPlaneMesh(raster, func(x, y float64) float64 { return plane(x, y) + 1 })
+		report, err := EvaluateMesh(mesh, src, &AccuracyOptions{
+			Percentiles: []float64{50, 100},
+			ErrorRaster: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Count != 80 {
+			t.Errorf("有效单元数错误: 预期 80, 实际 %d", report.Count)
+		}
+		if math.Abs(report.MaxAbsError-4) > 1e-9 || report.MaxErrorRow != 4 || report.MaxErrorCol != 4 {
+			t.Errorf("最大误差错误: %.4f @ (%d,%d)", report.MaxAbsError, report.MaxErrorRow, report.MaxErrorCol)
+		}
+		if math.Abs(report.MeanError-(79+4)/80.0) > 1e-9 {
+			t.Errorf("平均误差错误: %.6f", report.MeanError)
+		}
+		if math.Abs(report.RMSE-math.Sqrt((79+16)/80.0)) > 1e-9 {
+			t.Errorf("RMSE错误: %.6f", report.RMSE)
+		}
+		if math.Abs(report.Percentiles[50]-1) > 1e-9 || math.Abs(report.Percentiles[100]-4) > 1e-9 {
+			t.Errorf("百分位错误: %v", report.Percentiles)
+		}
+		if !math.IsNaN(report.ErrorRaster.Value(0, 0)) {
+			t.Error("无效值单元在误差栅格中应为 NaN")
+		}
+		if math.Abs(report.ErrorRaster.Value(2, 3)-1) > 1e-9 {
+			t.Errorf("误差栅格值错误: %.4f", report.ErrorRaster.Value(2, 3))
+		}
+	})
+}
+
+func TestZemlyaMeshEvaluate(t *testing.T) {
+	z := NewZemlyaMesh(&GeoConfig{})
+	z.LoadRaster(createWaveRaster())
+	z.GreedyInsert(0.5)
+
+	report, err := z.Evaluate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count != z.Raster.Count() {
+		t.Errorf("应覆盖全部单元: %d != %d", report.Count, z.Raster.Count())
+	}
+	if math.Abs(report.MaxAbsError-z.AchievedError) > 1e-9 {
+		t.Errorf("最大误差与 AchievedError 不一致: %.6f != %.6f", report.MaxAbsError, z.AchievedError)
+	}
+	if report.RMSE > report.MaxAbsError || report.MeanAbsError > report.RMSE {
+		t.Errorf("统计量关系错误: %v", report)
+	}
+	if report.Percentiles[50] > report.Percentiles[99] {
+		t.Errorf("百分位应单调: %v", report.Percentiles)
+	}
+
+	// 与 Mesh 评估结果一致
+	meshReport, err := EvaluateMesh(z.ToMesh(), z.Raster, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(meshReport.MaxAbsError-report.MaxAbsError) > 1e-6 {
+		t.Errorf("Mesh评估与三角剖分评估不一致: %.6f != %.6f", meshReport.MaxAbsError, report.MaxAbsError)
+	}
+}
@@ -0,0 +1,254 @@
+package tin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// 等高线，Closed 为 true 时首尾点相同
+// 线的走向保证高处位于左侧
+type ContourLine struct {
+	Level  float64
+	Points []Vertex
+	Closed bool
+}
+
+// 生成 base + k*interval 形式且位于 [minZ, maxZ] 内的等高线高程
+func ContourLevels(minZ, maxZ, interval, base float64) ([]float64, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid contour interval: %.6f", interval)
+	}
+	if minZ > maxZ {
+		return nil, nil
+	}
+	var levels []float64
+	first := math.Ceil((minZ - base) / interval)
+	last := math.Floor((maxZ - base) / interval)
+	for k := first; k <= last; k++ {
+		levels = append(levels, base+k*interval)
+	}
+	return levels, nil
+}
+
+// 按等间距提取等高线
+func MeshContoursInterval(mesh *Mesh, interval, base float64) ([]ContourLine, error) {
+	if mesh == nil {
+		return nil, fmt.Errorf("nil mesh")
+	}
+	if !mesh.hasDecomposed() {
+		mesh.GenerateDecomposed()
+	}
+	minZ, maxZ := math.Inf(1), math.Inf(-1)
+	for _, v := range mesh.Vertices {
+		minZ = math.Min(minZ, v[2])
+		maxZ = math.Max(maxZ, v[2])
+	}
+	levels, err := ContourLevels(minZ, maxZ, interval, base)
+	if err != nil {
+		return nil, err
+	}
+	return MeshContours(mesh, levels)
+}
+
+// 按指定高程提取等高线
+// 恰好位于某高程上的顶点视为高于该高程，保证每个三角形与等值面至多产生一条线段
+func MeshContours(mesh *Mesh, levels []float64) ([]ContourLine, error) {
+	if mesh == nil {
+		return nil, fmt.Errorf("nil mesh")
+	}
+	if !mesh.hasDecomposed() {
+		mesh.GenerateDecomposed()
+	}
+
+	var lines []ContourLine
+	for _, level := range levels {
+		lines = append(lines, meshContour(mesh, level)...)
+	}
+	return lines, nil
+}
+
+type contourEdge [2]VertexIndex
+
+func newContourEdge(a, b VertexIndex) contourEdge {
+	if a > b {
+		a, b = b, a
+	}
+	return contourEdge{a, b}
+}
+
+// 单个三角形内的等高线线段，从 from 边指向 to 边
+type contourSegment struct {
+	from, to contourEdge
+}
+
+func meshContour(mesh *Mesh, level float64) []ContourLine {
+	above := func(i VertexIndex) bool {
+		return mesh.Vertices[i][2] >= level
+	}
+
+	// 逐三角形求线段，按逆时针顶点顺序由 "高->低" 边指向 "低->高" 边
+	var segments []contourSegment
+	for _, f := range mesh.Faces {
+		v := [3]Vertex{mesh.Vertices[f[0]], mesh.Vertices[f[1]], mesh.Vertices[f[2]]}
+		o := Orientation([2]float64{v[0][0], v[0][1]}, [2]float64{v[1][0], v[1][1]}, [2]float64{v[2][0], v[2][1]})
+		if o == 0 {
+			continue
+		}
+		idx := f
+		if o < 0 {
+			idx[1], idx[2] = idx[2], idx[1]
+		}
+
+		var seg contourSegment
+		n := 0
+		for i := 0; i < 3; i++ {
+			a, b := idx[i], idx[(i+1)%3]
+			switch {
+			case above(a) && !above(b):
+				seg.from = newContourEdge(a, b)
+				n++
+			case !above(a) && above(b):
+				seg.to = newContourEdge(a, b)
+				n++
+			}
+		}
+		if n == 2 {
+			segments = append(segments, seg)
+		}
+	}
+
+	startAt := make(map[contourEdge]int, len(segments))
+	endAt := make(map[contourEdge]int, len(segments))
+	for i, s := range segments {
+		startAt[s.from] = i
+		endAt[s.to] = i
+	}
+
+	crossing := func(e contourEdge) Vertex {
+		a, b := mesh.Vertices[e[0]], mesh.Vertices[e[1]]
+		if a[2] == level {
+			return Vertex{a[0], a[1], level}
+		}
+		if b[2] == level {
+			return Vertex{b[0], b[1], level}
+		}
+		t := (level - a[2]) / (b[2] - a[2])
+		return Vertex{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1]), level}
+	}
+
+	visited := make([]bool, len(segments))
+	trace := func(start int) ContourLine {
+		line := ContourLine{Level: level}
+		pts := []Vertex{crossing(segments[start].from)}
+		cur := start
+		for {
+			visited[cur] = true
+			pts = append(pts, crossing(segments[cur].to))
+			next, ok := startAt[segments[cur].to]
+			if !ok {
+				break
+			}
+			if next == start {
+				line.Closed = true
+				break
+			}
+			if visited[next] {
+				break
+			}
+			cur = next
+		}
+		line.Points = cleanContourPoints(pts)
+		return line
+	}
+
+	var lines []ContourLine
+	emit := func(l ContourLine) {
+		if l.Closed && len(l.Points) < 4 {
+			return
+		}
+		if len(l.Points) < 2 {
+			return
+		}
+		lines = append(lines, l)
+	}
+
+	// 先从网格边界出发追踪开放线，剩余线段构成闭合环
+	for i, s := range segments {
+		if _, ok := endAt[s.from]; !ok && !visited[i] {
+			emit(trace(i))
+		}
+	}
+	for i := range segments {
+		if !visited[i] {
+			emit(trace(i))
+		}
+	}
+	return lines
+}
+
+// 去除顶点位于等值面时产生的重复点与原路折返
+func cleanContourPoints(pts []Vertex) []Vertex {
+	out := make([]Vertex, 0, len(pts))
+	for _, p := range pts {
+		n := len(out)
+		if n > 0 && out[n-1].Equal(p) {
+			continue
+		}
+		if n > 1 && out[n-2].Equal(p) {
+			out = out[:n-1]
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONLineString      `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONLineString struct {
+	Type        string       `json:"type"`
+	Coordinates [][3]float64 `json:"coordinates"`
+}
+
+// 将等高线编码为 GeoJSON FeatureCollection，每条线为一个 LineString
+// 属性 elevation 为等高线高程，closed 表示是否闭合；坐标与 Mesh 顶点坐标一致
+func ContoursToGeoJSON(lines []ContourLine) ([]byte, error) {
+	fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: make([]geoJSONFeature, 0, len(lines))}
+	for _, l := range lines {
+		coords := make([][3]float64, len(l.Points))
+		for i, p := range l.Points {
+			coords[i] = p
+		}
+		fc.Features = append(fc.Features, geoJSONFeature{
+			Type:     "Feature",
+			Geometry: geoJSONLineString{Type: "LineString", Coordinates: coords},
+			Properties: map[string]interface{}{
+				"elevation": l.Level,
+				"closed":    l.Closed,
+			},
+		})
+	}
+	return json.Marshal(fc)
+}
+
+func ExportContoursGeoJSON(filename string, lines []ContourLine) error {
+	data, err := ContoursToGeoJSON(lines)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	return nil
+}
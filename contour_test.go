@@ -0,0 +1,165 @@
+package tin
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// 在 [0, n] x [0, n] 的整数网格上按 h 生成三角网
+func createGridMesh(n int, h func(x, y float64) float64) *Mesh {
+	var tris []Triangle
+	for y := 0.0; y < float64(n); y++ {
+		for x := 0.0; x < float64(n); x++ {
+			a := Vertex{x, y, h(x, y)}
+			b := Vertex{x + 1, y, h(x+1, y)}
+			c := Vertex{x + 1, y + 1, h(x+1, y+1)}
+			d := Vertex{x, y + 1, h(x, y+1)}
+			tris = append(tris, Triangle{a, b, c}, Triangle{a, c, d})
+		}
+	}
+	mesh := &Mesh{}
+	mesh.InitFromTriangles(tris)
+	return mesh
+}
+
+func TestContourLevels(t *testing.T) {
+	levels, err := ContourLevels(3.2, 17, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{6, 11, 16}
+	if len(levels) != len(expected) {
+		t.Fatalf("高程数错误: %v", levels)
+	}
+	for i := range expected {
+		if levels[i] != expected[i] {
+			t.Errorf("高程 %d 错误: 预期 %.1f, 实际 %.1f", i, expected[i], levels[i])
+		}
+	}
+	if _, err := ContourLevels(0, 1, 0, 0); err == nil {
+		t.Error("间距为 0 应返回错误")
+	}
+}
+
+func TestMeshContours(t *testing.T) {
+	t.Run("ClosedRing", func(t *testing.T) {
+		mesh := createGridMesh(2, func(x, y float64) float64 {
+			if x == 1 && y == 1 {
+				return 4
+			}
+			return 0
+		})
+		lines, err := MeshContours(mesh, []float64{2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 1 {
+			t.Fatalf("预期 1 条等高线, 实际 %d", len(lines))
+		}
+		l := lines[0]
+		if !l.Closed || !l.Points[0].Equal(l.Points[len(l.Points)-1]) {
+			t.Fatalf("等高线应闭合: %v", l.Points)
+		}
+		// 中心点有 6 条相邻边
+		if len(l.Points) != 7 {
+			t.Errorf("预期 7 个点, 实际 %d", len(l.Points))
+		}
+		area := 0.0
+		for i := 1; i < len(l.Points); i++ {
+			p, q := l.Points[i-1], l.Points[i]
+			area += p[0]*q[1] - q[0]*p[1]
+			if p[2] != 2 {
+				t.Errorf("点高程错误: %v", p)
+			}
+			if math.Abs(math.Max(math.Abs(p[0]-1), math.Abs(p[1]-1))-0.5) > 1e-9 {
+				t.Errorf("点位置错误: %v", p)
+			}
+		}
+		// 高处在左侧，环绕山顶应为逆时针
+		if area <= 0 {
+			t.Errorf("闭合等高线应为逆时针: area=%.4f", area)
+		}
+	})
+
+	t.Run("VertexOnLevel", func(t *testing.T) {
+		mesh := createGridMesh(2, func(x, y float64) float64 { return x })
+		lines, err := MeshContours(mesh, []float64{1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 1 {
+			t.Fatalf("预期 1 条等高线, 实际 %d", len(lines))
+		}
+		l := lines[0]
+		if l.Closed {
+			t.Error("到达网格边界的等高线不应闭合")
+		}
+		expected := []Vertex{{1, 2, 1}, {1, 1, 1}, {1, 0, 1}}
+		if len(l.Points) != len(expected) {
+			t.Fatalf("点数错误: %v", l.Points)
+		}
+		for i := range expected {
+			if !l.Points[i].Equal(expected[i]) {
+				t.Errorf("点 %d 错误: 预期 %v, 实际 %v", i, expected[i], l.Points[i])
+			}
+		}
+	})
+
+	t.Run("IntervalAndGeoJSON", func(t *testing.T) {
+		mesh := createGridMesh(8, func(x, y float64) float64 {
+			return 10*math.Sin(x/3) + 5*math.Cos(y/2)
+		})
+		lines, err := MeshContoursInterval(mesh, 2.5, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) == 0 {
+			t.Fatal("未生成等高线")
+		}
+		for _, l := range lines {
+			if math.Mod(l.Level, 2.5) != 0 {
+				t.Errorf("高程不是间距的整数倍: %.3f", l.Level)
+			}
+			for _, p := range l.Points {
+				if p[2] != l.Level {
+					t.Errorf("点高程与等高线不符: %v, %.3f", p, l.Level)
+				}
+				if !l.Closed && p[0] > 0 && p[0] < 8 && p[1] > 0 && p[1] < 8 &&
+					(p.Equal(l.Points[0]) || p.Equal(l.Points[len(l.Points)-1])) {
+					t.Errorf("开放等高线端点应位于网格边界: %v", p)
+				}
+			}
+		}
+
+		data, err := ContoursToGeoJSON(lines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fc struct {
+			Type     string
+			Features []struct {
+				Geometry struct {
+					Type        string
+					Coordinates [][3]float64
+				}
+				Properties struct {
+					Elevation float64
+					Closed    bool
+				}
+			}
+		}
+		if err := json.Unmarshal(data, &fc); err != nil {
+			t.Fatal(err)
+		}
+		if fc.Type != "FeatureCollection" || len(fc.Features) != len(lines) {
+			t.Fatalf("GeoJSON 内容错误: %s", data)
+		}
+		for i, f := range fc.Features {
+			if f.Geometry.Type != "LineString" || f.Properties.Elevation != lines[i].Level ||
+				f.Properties.Closed != lines[i].Closed || len(f.Geometry.Coordinates) != len(lines[i].Points) {
+				t.Errorf("要素 %d 与等高线不符", i)
+			}
+		}
+	})
+}
@@ -1,21 +1,18 @@
 package tin
 
-import (
-	"math"
-	"math/rand"
-)
+import "math"
 
 type DelaunayTriangle struct {
 	Anchor *QuadEdge
 	Next   *DelaunayTriangle
-	pool   *Pool
-	index  int
+	prev   *DelaunayTriangle // 面链表中的前一个，删除面时使用
+	index  int               // 在 arena 中的下标
 }
 
-func NewDelaunayTriangle(p *Pool) *DelaunayTriangle {
-	ptr := &DelaunayTriangle{pool: p, index: len(p.Values)}
-	p.Values = append(p.Values, ptr)
-	return ptr
+func NewDelaunayTriangle(a *Arena[DelaunayTriangle]) *DelaunayTriangle {
+	index, t := a.alloc()
+	t.index = index
+	return t
 }
 
 func (t *DelaunayTriangle) init(e *QuadEdge) {
@@ -24,6 +21,10 @@ func (t *DelaunayTriangle) init(e *QuadEdge) {
 
 func (t *DelaunayTriangle) linkTo(o *DelaunayTriangle) *DelaunayTriangle {
 	t.Next = o
+	t.prev = nil
+	if o != nil {
+		o.prev = t
+	}
 	return t
 }
 
@@ -62,11 +63,20 @@ func (t *DelaunayTriangle) point3() [2]float64 {
 }
 
 type DelaunayMesh struct {
-	QuadEdges        *Pool
-	Triangles        *Pool
+	QuadEdges        *EdgeArena
+	Triangles        *Arena[DelaunayTriangle]
 	startingQuadEdge *QuadEdge
 	firstFace        *DelaunayTriangle
 	scanTriangle     func(*DelaunayTriangle)
+	// 设置时插入点周围的三角形一次性交给它扫描，顺序与逐个调用 scanTriangle 相同
+	scanTriangles func([]*DelaunayTriangle)
+	scanBuf       []*DelaunayTriangle
+
+	// 像元 X 方向相对 Y 方向的地面长度比，外接圆判定在此比例下进行，0 视为 1
+	aspect float64
+
+	// 定位随机游走的 xorshift 状态，initMesh 时重置，使同一输入的三角剖分可重现
+	walkState uint32
 }
 
 func (m *DelaunayMesh) makeFace(e *QuadEdge) *DelaunayTriangle {
@@ -77,10 +87,22 @@ func (m *DelaunayMesh) makeFace(e *QuadEdge) *DelaunayTriangle {
 	return t
 }
 
+// 从面链表中移除三角形并回收其记录，调用方需保证已没有边以它为左面
+func (m *DelaunayMesh) deleteFace(t *DelaunayTriangle) {
+	if t.prev != nil {
+		t.prev.Next = t.Next
+	} else {
+		m.firstFace = t.Next
+	}
+	if t.Next != nil {
+		t.Next.prev = t.prev
+	}
+	m.Triangles.release(t.index)
+}
+
 func (m *DelaunayMesh) delete(e *QuadEdge) {
 	Splice(e, e.OrigPrev())
 	Splice(e.Sym(), e.Sym().OrigPrev())
-	e.RecycleNext()
 	e.recycle()
 }
 
@@ -139,36 +161,43 @@ func (m *DelaunayMesh) onQuadEdge(x [2]float64, e *QuadEdge) bool {
 }
 
 func (m *DelaunayMesh) InitMeshFromBBox(bb BBox2d) {
+	// 与 ZemlyaMesh 相同，四个角点须按 initMesh 要求的环绕顺序给出
 	a := [2]float64{bb[0], bb[1]}
-	d := [2]float64{bb[2], bb[3]}
-	b := [2]float64{bb[2], bb[1]}
-	c := [2]float64{bb[0], bb[3]}
+	b := [2]float64{bb[0], bb[3]}
+	c := [2]float64{bb[2], bb[3]}
+	d := [2]float64{bb[2], bb[1]}
 	m.initMesh(a, b, c, d)
 }
 
+// 重新初始化时回收此前网格的全部边与三角形
 func (m *DelaunayMesh) initMesh(a, b, c, d [2]float64) {
-	ea := New(m.QuadEdges)
-	ea.Init()
+	if m.QuadEdges == nil {
+		m.QuadEdges = NewEdgeArena()
+	}
+	if m.Triangles == nil {
+		m.Triangles = NewArena[DelaunayTriangle]()
+	}
+	m.QuadEdges.Reset()
+	m.Triangles.Reset()
+	m.walkState = locateSeed
+
+	ea := m.QuadEdges.New()
 	ea.SetEndPoints(a, b)
 
-	eb := New(m.QuadEdges)
-	eb.Init()
+	eb := m.QuadEdges.New()
 	Splice(ea.Sym(), eb)
 	eb.SetEndPoints(b, c)
 
-	ec := New(m.QuadEdges)
-	ec.Init()
+	ec := m.QuadEdges.New()
 	Splice(eb.Sym(), ec)
 	ec.SetEndPoints(c, d)
 
-	ed := New(m.QuadEdges)
-	ed.Init()
+	ed := m.QuadEdges.New()
 	Splice(ec.Sym(), ed)
 	ed.SetEndPoints(d, a)
 	Splice(ed.Sym(), ea)
 
-	diag := New(m.QuadEdges)
-	diag.Init()
+	diag := m.QuadEdges.New()
 	Splice(ed.Sym(), diag)
 	Splice(eb.Sym(), diag.Sym())
 	diag.SetEndPoints(a, c)
@@ -197,6 +226,9 @@ func (m *DelaunayMesh) insert(x [2]float64, tri *DelaunayTriangle) {
 	} else {
 		e = m.locate(x, m.startingQuadEdge)
 	}
+	if e.LeftFace() == nil {
+		return
+	}
 
 	if (isEqual(x, e.Orig())) || (isEqual(x, e.Dest())) {
 		m.optimize(x, e)
@@ -210,15 +242,33 @@ func (m *DelaunayMesh) insert(x [2]float64, tri *DelaunayTriangle) {
 
 func (m *DelaunayMesh) shouldSwap(x [2]float64, e *QuadEdge) bool {
 	t := e.OrigPrev()
-	return InCircumcircle(e.Orig(), t.Dest(), e.Dest(), x)
+	a, b, c := e.Orig(), t.Dest(), e.Dest()
+	if m.aspect > 0 && m.aspect != 1 {
+		// 方向判定不受轴向缩放影响，只有外接圆判定需要换算到地面比例
+		a[0] *= m.aspect
+		b[0] *= m.aspect
+		c[0] *= m.aspect
+		x[0] *= m.aspect
+	}
+	return InCircumcircle(a, b, c, x)
 }
 
 func triArea(a, b, c [2]float64) float64 {
 	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
 }
 
-func nextRandomNumber() uint32 {
-	return rand.Uint32() % math.MaxUint32
+const locateSeed = 2463534242
+
+func (m *DelaunayMesh) nextRandomNumber() uint32 {
+	if m.walkState == 0 {
+		m.walkState = locateSeed
+	}
+	x := m.walkState
+	x ^= x << 13
+	x ^= x >> 17
+	x ^= x << 5
+	m.walkState = x
+	return x
 }
 
 func (m *DelaunayMesh) locate(x [2]float64, e *QuadEdge) *QuadEdge {
@@ -230,6 +280,12 @@ func (m *DelaunayMesh) locate(x [2]float64, e *QuadEdge) *QuadEdge {
 	}
 
 	for {
+		// x 严格位于凸包边 e 的外侧，即在三角网之外
+		if t < 0 && e.LeftFace() == nil {
+			m.startingQuadEdge = e
+			return e
+		}
+
 		eo := e.OrigNext()
 		ed := e.DestPrev()
 
@@ -256,7 +312,7 @@ func (m *DelaunayMesh) locate(x [2]float64, e *QuadEdge) *QuadEdge {
 			} else {
 				if t == 0 && !leftOf(eo.Dest(), e) {
 					e = e.Sym()
-				} else if (nextRandomNumber() & 1) == 0 {
+				} else if (m.nextRandomNumber() & 1) == 0 {
 					t = to
 					e = eo
 				} else {
@@ -292,8 +348,7 @@ func (m *DelaunayMesh) spoke(x [2]float64, e *QuadEdge) *QuadEdge {
 		}
 	}
 
-	base := New(m.QuadEdges)
-	base.Init()
+	base := m.QuadEdges.New()
 
 	base.SetEndPoints(e.Orig(), x)
 
@@ -332,6 +387,12 @@ func (m *DelaunayMesh) spoke(x [2]float64, e *QuadEdge) *QuadEdge {
 		}
 	}
 
+	// 退化情形下新面少于被拆分的旧面，多余的旧面已不被任何边引用
+	for facedex > 0 {
+		facedex--
+		m.deleteFace(newFaces[facedex])
+	}
+
 	return m.startingQuadEdge
 }
 
@@ -353,12 +414,13 @@ func (m *DelaunayMesh) optimize(x [2]float64, s *QuadEdge) {
 
 	spoke = startSpoke
 
+	m.scanBuf = m.scanBuf[:0]
 	for {
 		e := spoke.LeftNext()
 		t := e.LeftFace()
 
 		if t != nil {
-			m.scanTriangle(t)
+			m.scanBuf = append(m.scanBuf, t)
 		}
 
 		spoke = spoke.OrigNext()
@@ -366,6 +428,13 @@ func (m *DelaunayMesh) optimize(x [2]float64, s *QuadEdge) {
 			break
 		}
 	}
+	if m.scanTriangles != nil {
+		m.scanTriangles(m.scanBuf)
+		return
+	}
+	for _, t := range m.scanBuf {
+		m.scanTriangle(t)
+	}
 }
 
 func (m *DelaunayMesh) Insert(x [2]float64, tri *DelaunayTriangle) {
@@ -375,6 +444,9 @@ func (m *DelaunayMesh) Insert(x [2]float64, tri *DelaunayTriangle) {
 	} else {
 		e = m.locate(x, m.startingQuadEdge)
 	}
+	if e.LeftFace() == nil {
+		return
+	}
 
 	if isEqual(x, e.Orig()) || isEqual(x, e.Dest()) {
 		m.optimize(x, e)
@@ -2,12 +2,13 @@ package tin
 
 import (
 	"testing"
+	"time"
 )
 
 func TestDelaunayMesh(t *testing.T) {
 	// 创建边和三角形的对象池
-	edgePool := NewPool(func() interface{} { return &QuadEdge{} })
-	trianglePool := NewPool(func() interface{} { return &DelaunayTriangle{} })
+	edgePool := NewEdgeArena()
+	trianglePool := NewArena[DelaunayTriangle]()
 
 	// 初始化网格
 	mesh := &DelaunayMesh{
@@ -190,3 +191,45 @@ func TestDelaunayMesh(t *testing.T) {
 	})
 
 }
+
+// InitMeshFromBBox 须按 initMesh 要求的环绕顺序给出角点，凸包外的点不应使定位死循环
+func TestDelaunayBBoxInsert(t *testing.T) {
+	mesh := &DelaunayMesh{
+		QuadEdges:    NewEdgeArena(),
+		Triangles:    NewArena[DelaunayTriangle](),
+		scanTriangle: func(*DelaunayTriangle) {},
+	}
+	mesh.InitMeshFromBBox(BBox2d{0, 0, 10, 10})
+
+	k := 0
+	for y := 1.5; y < 10; y += 2 {
+		for x := 1; x < 10; x += 2 {
+			mesh.Insert([2]float64{float64(x), y}, nil)
+			k++
+		}
+	}
+	// 4 个角点构成凸包: T = 2V - 2 - 4
+	if n := faceCount(mesh); n != 2*k+2 {
+		t.Fatalf("三角形数错误: 预期 %d, 实际 %d", 2*k+2, n)
+	}
+	mesh.ForEachTriangle(func(tri *DelaunayTriangle) {
+		if triArea(tri.point1(), tri.point2(), tri.point3()) <= 0 {
+			t.Fatalf("三角形 %v %v %v 不是逆时针", tri.point1(), tri.point2(), tri.point3())
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		mesh.Insert([2]float64{12, 5}, nil)
+		mesh.Insert([2]float64{5, -3}, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("插入凸包外的点时定位未终止")
+	}
+	if n := faceCount(mesh); n != 2*k+2 {
+		t.Errorf("凸包外的点不应插入: 三角形数 %d, 预期 %d", n, 2*k+2)
+	}
+}
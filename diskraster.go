@@ -0,0 +1,401 @@
+package tin
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/flywave/go-geo"
+	vec2d "github.com/flywave/go3d/float64/vec2"
+)
+
+const (
+	tiledRasterMagic      = "TINR"
+	tiledRasterVersion    = 2
+	tiledRasterHeaderSize = 128
+)
+
+// 分块栅格文件头，固定占用 tiledRasterHeaderSize 字节
+type tiledRasterHeader struct {
+	Magic        [4]byte
+	Version      uint16
+	DataType     uint16
+	Rows         int64
+	Cols         int64
+	TileRows     uint32
+	TileCols     uint32
+	NoData       float64
+	GeoTransform GeoTransform
+	PixelIsPoint bool
+	Hemlines     bool
+}
+
+// 分块存储在磁盘上的栅格，按窗口读写，适合超出内存的 DEM
+// 文件由文件头与按行排列的数据块组成，块内按行存储小端序像元，边缘块补齐为完整大小
+// 只读打开时尽量使用内存映射，否则按需读取文件
+type TiledRaster[T RasterElement] struct {
+	RasterGrid
+	NoData   T
+	TileRows int
+	TileCols int
+
+	file     *os.File
+	mapped   []byte
+	writable bool
+	elemSize int
+}
+
+// 创建以 noData 填充的分块栅格文件，地理参考可在 Close 前通过 SetGeoTransform 等设置
+func CreateTiledRaster[T RasterElement](path string, rows, cols, tileSize int, noData T) (*TiledRaster[T], error) {
+	if rows <= 0 || cols <= 0 || tileSize <= 0 {
+		return nil, fmt.Errorf("invalid tiled raster size: %dx%d tile=%d", rows, cols, tileSize)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建文件失败: %v", err)
+	}
+	t := &TiledRaster[T]{NoData: noData, TileRows: tileSize, TileCols: tileSize, file: f, writable: true}
+	t.Size = [2]int{rows, cols}
+	t.elemSize = binary.Size(noData)
+
+	tile := make([]T, tileSize*tileSize)
+	for i := range tile {
+		tile[i] = noData
+	}
+	buf, err := binary.Append(nil, binary.LittleEndian, tile)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	tilesDown, tilesAcross := t.tileCounts()
+	for i := 0; i < tilesDown*tilesAcross; i++ {
+		if _, err := f.WriteAt(buf, tiledRasterHeaderSize+int64(i)*int64(len(buf))); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("写入文件失败: %v", err)
+		}
+	}
+	if err := t.writeHeader(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// 将内存栅格写为分块栅格文件
+func WriteTiledRaster[T RasterElement](path string, src *RasterOf[T], tileSize int) error {
+	t, err := CreateTiledRaster(path, src.Rows(), src.Cols(), tileSize, src.NoData)
+	if err != nil {
+		return err
+	}
+	t.copyGeoReference(&src.RasterGrid)
+	if err := t.WriteWindow(0, 0, src); err != nil {
+		t.Close()
+		return err
+	}
+	return t.Close()
+}
+
+// 只读打开分块栅格文件，像元类型须与文件一致
+func OpenTiledRaster[T RasterElement](path string) (*TiledRaster[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	var h tiledRasterHeader
+	if err := binary.Read(io.NewSectionReader(f, 0, tiledRasterHeaderSize), binary.LittleEndian, &h); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("读取文件头失败: %v", err)
+	}
+	if string(h.Magic[:]) != tiledRasterMagic || h.Version != tiledRasterVersion {
+		f.Close()
+		return nil, fmt.Errorf("not a tiled raster file: %s", path)
+	}
+	if int(h.DataType) != RasterDataType[T]() {
+		f.Close()
+		return nil, fmt.Errorf("raster data type mismatch: file %d, requested %d", h.DataType, RasterDataType[T]())
+	}
+
+	t := &TiledRaster[T]{
+		NoData:   T(h.NoData),
+		TileRows: int(h.TileRows),
+		TileCols: int(h.TileCols),
+		file:     f,
+	}
+	if math.IsNaN(h.NoData) {
+		t.NoData = defaultNoData[T]()
+	}
+	t.elemSize = binary.Size(t.NoData)
+	t.Size = [2]int{int(h.Rows), int(h.Cols)}
+	t.Hemlines = h.Hemlines
+	t.pixelIsPoint = h.PixelIsPoint
+	t.SetGeoTransform(h.GeoTransform)
+
+	if h.Rows <= 0 || h.Cols <= 0 || t.TileRows <= 0 || t.TileCols <= 0 {
+		f.Close()
+		return nil, fmt.Errorf("invalid tiled raster size: %dx%d, tile %dx%d", h.Rows, h.Cols, t.TileRows, t.TileCols)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("读取文件信息失败: %v", err)
+	}
+	// 文件头中的尺寸不可信，数据区须覆盖全部数据块，否则读取时会越界
+	tilesDown, tilesAcross := t.tileCounts()
+	want := tiledRasterHeaderSize + int64(tilesDown)*int64(tilesAcross)*int64(t.TileRows)*int64(t.TileCols)*int64(t.elemSize)
+	if info.Size() < want {
+		f.Close()
+		return nil, fmt.Errorf("tiled raster truncated: %d bytes, want %d", info.Size(), want)
+	}
+	if data, err := mmapFile(f, info.Size()); err == nil {
+		t.mapped = data
+	}
+	return t, nil
+}
+
+func (t *TiledRaster[T]) tileCounts() (int, int) {
+	return (t.Rows() + t.TileRows - 1) / t.TileRows, (t.Cols() + t.TileCols - 1) / t.TileCols
+}
+
+// 像元 (row, col) 在文件中的字节偏移
+func (t *TiledRaster[T]) offset(row, col int) int64 {
+	_, tilesAcross := t.tileCounts()
+	tile := int64(row/t.TileRows)*int64(tilesAcross) + int64(col/t.TileCols)
+	inTile := int64(row%t.TileRows)*int64(t.TileCols) + int64(col%t.TileCols)
+	return tiledRasterHeaderSize + (tile*int64(t.TileRows*t.TileCols)+inTile)*int64(t.elemSize)
+}
+
+func (t *TiledRaster[T]) writeHeader() error {
+	h := tiledRasterHeader{
+		Version:      tiledRasterVersion,
+		DataType:     uint16(RasterDataType[T]()),
+		Rows:         int64(t.Rows()),
+		Cols:         int64(t.Cols()),
+		TileRows:     uint32(t.TileRows),
+		TileCols:     uint32(t.TileCols),
+		NoData:       float64(t.NoData),
+		GeoTransform: t.geoTransform,
+		PixelIsPoint: t.pixelIsPoint,
+		Hemlines:     t.Hemlines,
+	}
+	copy(h.Magic[:], tiledRasterMagic)
+	buf, err := binary.Append(make([]byte, 0, tiledRasterHeaderSize), binary.LittleEndian, &h)
+	if err != nil {
+		return err
+	}
+	buf = append(buf, make([]byte, tiledRasterHeaderSize-len(buf))...)
+	if _, err := t.file.WriteAt(buf, 0); err != nil {
+		return fmt.Errorf("写入文件头失败: %v", err)
+	}
+	return nil
+}
+
+func (t *TiledRaster[T]) checkWindow(row, col, rows, cols int) error {
+	if row < 0 || col < 0 || rows <= 0 || cols <= 0 || row+rows > t.Rows() || col+cols > t.Cols() {
+		return fmt.Errorf("window (%d, %d, %d, %d) is outside the %dx%d raster", row, col, rows, cols, t.Rows(), t.Cols())
+	}
+	return nil
+}
+
+// 读取从 (row, col) 开始 rows 行 cols 列的窗口，返回带对应地理参考的内存栅格
+func (t *TiledRaster[T]) ReadWindow(row, col, rows, cols int) (*RasterOf[T], error) {
+	if err := t.checkWindow(row, col, rows, cols); err != nil {
+		return nil, err
+	}
+	win := newRasterOf(rows, cols, make([]T, rows*cols), t.NoData)
+	win.setWindowGeoReference(&t.RasterGrid, row, col, 1)
+
+	var buf []byte
+	if t.mapped == nil {
+		buf = make([]byte, t.TileCols*t.elemSize)
+	}
+	for r := row; r < row+rows; r++ {
+		for c := col; c < col+cols; {
+			// 同一数据块内的一段连续像元
+			n := MinInt(t.TileCols-c%t.TileCols, col+cols-c)
+			off := t.offset(r, c)
+			size := n * t.elemSize
+			var src []byte
+			if t.mapped != nil {
+				src = t.mapped[off : off+int64(size)]
+			} else {
+				if _, err := t.file.ReadAt(buf[:size], off); err != nil {
+					return nil, fmt.Errorf("读取文件失败: %v", err)
+				}
+				src = buf[:size]
+			}
+			dst := win.Data[(r-row)*cols+(c-col):][:n]
+			if _, err := binary.Decode(src, binary.LittleEndian, dst); err != nil {
+				return nil, err
+			}
+			c += n
+		}
+	}
+	return win, nil
+}
+
+// 读取窗口并按 factor x factor 像元块降采样，NoData 不参与计算
+// 每次只读取 factor 行，内存占用与窗口宽度成正比
+func (t *TiledRaster[T]) ReadWindowDecimated(row, col, rows, cols, factor int) (*RasterOf[T], error) {
+	if factor <= 1 {
+		return t.ReadWindow(row, col, rows, cols)
+	}
+	if err := t.checkWindow(row, col, rows, cols); err != nil {
+		return nil, err
+	}
+	outRows, outCols := (rows+factor-1)/factor, (cols+factor-1)/factor
+	out := NewRasterOf(outRows, outCols, t.NoData)
+	out.setWindowGeoReference(&t.RasterGrid, row, col, factor)
+
+	sums := make([]float64, outCols)
+	counts := make([]int, outCols)
+	for or := 0; or < outRows; or++ {
+		n := MinInt(factor, rows-or*factor)
+		strip, err := t.ReadWindow(row+or*factor, col, n, cols)
+		if err != nil {
+			return nil, err
+		}
+		for i := range sums {
+			sums[i], counts[i] = 0, 0
+		}
+		for i, v := range strip.Data {
+			if strip.IsNoData(v) {
+				continue
+			}
+			oc := (i % cols) / factor
+			sums[oc] += float64(v)
+			counts[oc]++
+		}
+		for oc := range sums {
+			if counts[oc] > 0 {
+				out.SetElevation(or, oc, sums[oc]/float64(counts[oc]))
+			}
+		}
+	}
+	return out, nil
+}
+
+// 将内存栅格写入 (row, col) 开始的窗口，仅 CreateTiledRaster 打开的栅格可写
+func (t *TiledRaster[T]) WriteWindow(row, col int, src *RasterOf[T]) error {
+	if !t.writable {
+		return fmt.Errorf("tiled raster is read-only")
+	}
+	rows, cols := src.Rows(), src.Cols()
+	if err := t.checkWindow(row, col, rows, cols); err != nil {
+		return err
+	}
+	buf := make([]byte, 0, t.TileCols*t.elemSize)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; {
+			n := MinInt(t.TileCols-(col+c)%t.TileCols, cols-c)
+			var err error
+			buf, err = binary.Append(buf[:0], binary.LittleEndian, src.Data[r*cols+c:][:n])
+			if err != nil {
+				return err
+			}
+			if _, err := t.file.WriteAt(buf, t.offset(row+r, col+c)); err != nil {
+				return fmt.Errorf("写入文件失败: %v", err)
+			}
+			c += n
+		}
+	}
+	return nil
+}
+
+// 可写时写回文件头 (含地理参考)，然后释放映射并关闭文件
+func (t *TiledRaster[T]) Close() error {
+	var err error
+	if t.writable {
+		err = t.writeHeader()
+	}
+	if t.mapped != nil {
+		munmapFile(t.mapped)
+		t.mapped = nil
+	}
+	if cerr := t.file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("关闭文件失败: %v", cerr)
+	}
+	return err
+}
+
+// 按瓦片范围从分块栅格中惰性读取窗口的 DEM 数据源
+// 低层级按瓦片网格分辨率以 2 的幂次降采样读取，坐标系不同时重投影到瓦片网格
+type TiledRasterProvider[T RasterElement] struct {
+	Source     *TiledRaster[T]
+	Srs        geo.Proj // 源栅格坐标系，nil 表示与瓦片网格相同
+	TileGrid   *geo.TileGrid
+	Scale      float64 // 高程 = 像元值*Scale + Offset
+	Offset     float64
+	Resampling ResampleMethod
+}
+
+func NewTiledRasterProvider[T RasterElement](tileGrid *geo.TileGrid, srs geo.Proj, src *TiledRaster[T]) *TiledRasterProvider[T] {
+	return &TiledRasterProvider[T]{
+		Source:     src,
+		Srs:        srs,
+		TileGrid:   tileGrid,
+		Scale:      1,
+		Resampling: ResampleBilinear,
+	}
+}
+
+func (p *TiledRasterProvider[T]) reproject() bool {
+	return p.Srs != nil && p.TileGrid.Srs != nil && !p.Srs.Eq(p.TileGrid.Srs)
+}
+
+func (p *TiledRasterProvider[T]) GetDEM(bbox vec2d.Rect, zoom int) (*RasterDouble, error) {
+	src := p.Source
+	if src.minCellSize() <= 0 {
+		return nil, fmt.Errorf("invalid cellsize: %.6f", src.minCellSize())
+	}
+	cellSize := src.maxCellSize()
+
+	srcBBox := bbox
+	res := p.TileGrid.Resolution(zoom)
+	if p.reproject() {
+		srcBBox = p.TileGrid.Srs.TransformRectTo(p.Srs, bbox, 16)
+		if bbox.Width() > 0 {
+			res *= srcBBox.Width() / bbox.Width()
+		}
+	}
+	factor := 1
+	for float64(factor*2)*cellSize <= res*(1+EPS) {
+		factor *= 2
+	}
+
+	// 窗口向外扩展一个降采样像元，保证插值与边界对齐
+	// 起止点均对齐到 factor 的整数倍，边缘像元与相邻瓦片取相同的块均值
+	row0, col0, row1, col1, err := src.pixelWindow(srcBBox)
+	if err != nil {
+		return nil, err
+	}
+	col0 = max(0, (col0-factor)/factor*factor)
+	row0 = max(0, (row0-factor)/factor*factor)
+	col1 = MinInt(src.Cols(), (col1+2*factor-1)/factor*factor)
+	row1 = MinInt(src.Rows(), (row1+2*factor-1)/factor*factor)
+
+	win, err := src.ReadWindowDecimated(row0, col0, row1-row0, col1-col0, factor)
+	if err != nil {
+		return nil, fmt.Errorf("DEM generation failed: %w", err)
+	}
+	dem := ToRasterDouble(win, p.Scale, p.Offset)
+	if !p.reproject() {
+		return dem, nil
+	}
+	return Warp(dem, &WarpOptions{
+		SrcSrs:   p.Srs,
+		DstSrs:   p.TileGrid.Srs,
+		Bounds:   bbox,
+		CellSize: p.TileGrid.Resolution(zoom),
+		Method:   p.Resampling,
+	})
+}
+
+func (p *TiledRasterProvider[T]) Coverage() (geo.Coverage, error) {
+	srs := p.Srs
+	if srs == nil {
+		srs = p.TileGrid.Srs
+	}
+	return geo.NewBBoxCoverage(p.Source.Extent(), srs, true), nil
+}
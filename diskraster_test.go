@@ -0,0 +1,285 @@
+package tin
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flywave/go-geo"
+	vec2d "github.com/flywave/go3d/float64/vec2"
+)
+
+func createTiledTestRaster(rows, cols int) *RasterShort {
+	r := NewRasterOf(rows, cols, int16(-9999))
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			r.SetValue(y, x, int16(y*100+x))
+		}
+	}
+	r.SetValue(5, 7, -9999)
+	r.SetXYPos(1000, 2000, 10)
+	return r
+}
+
+func TestTiledRasterReadWindow(t *testing.T) {
+	src := createTiledTestRaster(37, 53)
+	path := filepath.Join(t.TempDir(), "dem.tinr")
+	if err := WriteTiledRaster(path, src, 16); err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := OpenTiledRaster[int16](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+	if tr.Rows() != 37 || tr.Cols() != 53 || tr.NoData != -9999 || tr.CellSize() != 10 || tr.Bounds != src.Bounds {
+		t.Fatalf("文件头不一致: %v %v %v", tr.Size, tr.NoData, tr.Bounds)
+	}
+
+	windows := [][4]int{{0, 0, 37, 53}, {14, 10, 5, 30}, {30, 47, 7, 6}, {5, 7, 1, 1}}
+	for _, w := range windows {
+		win, err := tr.ReadWindow(w[0], w[1], w[2], w[3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for y := 0; y < w[2]; y++ {
+			for x := 0; x < w[3]; x++ {
+				if got, want := win.Value(y, x), src.Value(w[0]+y, w[1]+x); got != want {
+					t.Fatalf("窗口 %v (%d,%d): 期望 %d, 实际 %d", w, y, x, want, got)
+				}
+			}
+		}
+		// 窗口像元中心与源栅格对应像元中心一致
+		if win.ColToX(0) != src.ColToX(w[1]) || win.RowToY(0) != src.RowToY(w[0]) {
+			t.Errorf("窗口 %v 地理参考错误", w)
+		}
+	}
+	// 不使用内存映射时按需读取文件
+	if tr.mapped != nil {
+		munmapFile(tr.mapped)
+		tr.mapped = nil
+	}
+	win, err := tr.ReadWindow(14, 10, 5, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if win.Value(4, 29) != src.Value(18, 39) {
+		t.Errorf("文件读取结果错误: %d", win.Value(4, 29))
+	}
+
+	if _, err := tr.ReadWindow(30, 50, 8, 2); err == nil {
+		t.Error("越界窗口应返回错误")
+	}
+	if err := tr.WriteWindow(0, 0, src); err == nil {
+		t.Error("只读栅格不应可写")
+	}
+	if _, err := OpenTiledRaster[float32](path); err == nil {
+		t.Error("像元类型不一致应返回错误")
+	}
+}
+
+func TestTiledRasterWriteWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dem.tinr")
+	tr, err := CreateTiledRaster(path, 20, 20, 8, float32(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch := NewRasterOf(5, 6, float32(-1))
+	patch.Fill(42)
+	if err := tr.WriteWindow(6, 5, patch); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err = OpenTiledRaster[float32](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+	all, err := tr.ReadWindow(0, 0, 20, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			want := float32(-1)
+			if y >= 6 && y < 11 && x >= 5 && x < 11 {
+				want = 42
+			}
+			if all.Value(y, x) != want {
+				t.Fatalf("(%d,%d): 期望 %v, 实际 %v", y, x, want, all.Value(y, x))
+			}
+		}
+	}
+}
+
+func TestTiledRasterCorruptHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dem.tinr")
+	if err := WriteTiledRaster(path, createTiledTestRaster(20, 30), 8); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("截断", func(t *testing.T) {
+		if err := os.Truncate(path, info.Size()-1); err != nil {
+			t.Fatal(err)
+		}
+		if tr, err := OpenTiledRaster[int16](path); err == nil {
+			tr.Close()
+			t.Fatal("截断的文件应返回错误")
+		}
+	})
+
+	t.Run("数据块大小为0", func(t *testing.T) {
+		if err := WriteTiledRaster(path, createTiledTestRaster(20, 30), 8); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// TileRows 位于 Magic、Version、DataType、Rows、Cols 之后
+		_, err = f.WriteAt(make([]byte, 4), 24)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tr, err := OpenTiledRaster[int16](path); err == nil {
+			tr.Close()
+			t.Fatal("数据块大小为0时应返回错误")
+		}
+	})
+}
+
+func TestTiledRasterDecimated(t *testing.T) {
+	src := createTiledTestRaster(37, 53)
+	path := filepath.Join(t.TempDir(), "dem.tinr")
+	if err := WriteTiledRaster(path, src, 16); err != nil {
+		t.Fatal(err)
+	}
+	tr, err := OpenTiledRaster[int16](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+
+	dec, err := tr.ReadWindowDecimated(0, 0, 37, 53, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Downsample2x(ToRasterDouble(src, 1, 0), OverviewAverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Rows() != ref.Rows() || dec.Cols() != ref.Cols() || dec.CellSize() != 20 {
+		t.Fatalf("降采样尺寸错误: %v %v", dec.Size, ref.Size)
+	}
+	for y := 0; y < ref.Rows(); y++ {
+		for x := 0; x < ref.Cols(); x++ {
+			// 整型栅格四舍五入存储
+			if math.Abs(dec.Elevation(y, x)-ref.Value(y, x)) > 0.5 {
+				t.Fatalf("(%d,%d): 期望 %v, 实际 %v", y, x, ref.Value(y, x), dec.Elevation(y, x))
+			}
+		}
+	}
+	if dec.Bounds[0] != src.Bounds[0] || dec.Bounds[3] != src.Bounds[3] {
+		t.Errorf("降采样窗口应与源栅格左上角对齐: %v %v", dec.Bounds, src.Bounds)
+	}
+}
+
+func TestTiledRasterProvider(t *testing.T) {
+	// 源像元大小与第 14 级分辨率一致，该级直接读取原始像元
+	const zoom = 14
+	grid := geo.NewMercTileGrid()
+	res := grid.Resolution(zoom)
+	src := createTiledTestRaster(64, 64)
+	src.SetXYPos(1000, 2000, res)
+	path := filepath.Join(t.TempDir(), "dem.tinr")
+	if err := WriteTiledRaster(path, src, 16); err != nil {
+		t.Fatal(err)
+	}
+	tr, err := OpenTiledRaster[int16](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+
+	p := NewTiledRasterProvider(grid, nil, tr)
+	p.Scale = 0.01
+	// 覆盖第 2~20 行、第 4~24 列的单元中心，包含 (5, 7) 处的无效值
+	x0, y0 := src.CellCenter(20, 4)
+	x1, y1 := src.CellCenter(2, 24)
+	bbox := vec2d.Rect{Min: vec2d.T{x0, y0}, Max: vec2d.T{x1, y1}}
+
+	t.Run("原始分辨率", func(t *testing.T) {
+		dem, err := p.GetDEM(bbox, zoom)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dem.CellSize() != res {
+			t.Fatalf("像元大小应为 %v, 实际 %v", res, dem.CellSize())
+		}
+		if dem.Rows() < 19 || dem.Cols() < 21 || dem.Rows() >= 64 || dem.Cols() >= 64 {
+			t.Fatalf("应只读取瓦片附近的窗口: %v", dem.Size)
+		}
+		for y := 0; y < dem.Rows(); y++ {
+			for x := 0; x < dem.Cols(); x++ {
+				col, row := src.geoToPixel(dem.CellCenter(y, x))
+				want := src.Elevation(int(math.Round(row)), int(math.Round(col))) * 0.01
+				if got := dem.Value(y, x); got != want && !(math.IsNaN(want) && math.IsNaN(got)) {
+					t.Fatalf("(%d,%d): 期望 %v, 实际 %v", y, x, want, got)
+				}
+			}
+		}
+	})
+
+	t.Run("降采样", func(t *testing.T) {
+		// 上一级分辨率为源像元的 2 倍，每个像元取 2x2 块内有效值的均值，按 int16 四舍五入
+		dem, err := p.GetDEM(bbox, zoom-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dem.CellSize() != 2*res {
+			t.Fatalf("像元大小应为 %v, 实际 %v", 2*res, dem.CellSize())
+		}
+		if dem.Rows() < 10 || dem.Cols() < 11 || dem.Rows() >= 32 || dem.Cols() >= 32 {
+			t.Fatalf("降采样窗口大小错误: %v", dem.Size)
+		}
+		for y := 0; y < dem.Rows(); y++ {
+			for x := 0; x < dem.Cols(); x++ {
+				col, row := src.geoToPixel(dem.CellCenter(y, x))
+				r0, c0 := int(math.Floor(row)), int(math.Floor(col))
+				sum, n := 0.0, 0
+				for _, v := range []float64{src.Elevation(r0, c0), src.Elevation(r0, c0+1), src.Elevation(r0+1, c0), src.Elevation(r0+1, c0+1)} {
+					if !math.IsNaN(v) {
+						sum += v
+						n++
+					}
+				}
+				want := math.Round(sum/float64(n)) * 0.01
+				if got := dem.Value(y, x); got != want {
+					t.Fatalf("(%d,%d): 期望 %v, 实际 %v", y, x, want, got)
+				}
+			}
+		}
+		// (5, 7) 所在块只有三个有效值
+		col, row := dem.geoToPixel(src.CellCenter(5, 7))
+		if got, want := dem.Value(int(math.Round(row)), int(math.Round(col))), math.Round((406+407+506)/3.0)*0.01; got != want {
+			t.Errorf("含无效值的块: 期望 %v, 实际 %v", want, got)
+		}
+	})
+
+	if _, err := p.GetDEM(vec2d.Rect{Min: vec2d.T{0, 0}, Max: vec2d.T{100, 100}}, zoom); err == nil {
+		t.Error("不相交的瓦片应返回错误")
+	}
+	if _, err := p.Coverage(); err != nil {
+		t.Error(err)
+	}
+}
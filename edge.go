@@ -1,13 +1,13 @@
 package tin
 
 type QuadEdge struct {
-	pool  *Pool
+	arena *EdgeArena
 	qnext *QuadEdge
 	qprev *QuadEdge
 	next  *QuadEdge
 	data  [2]float64
 	lface *DelaunayTriangle
-	index int
+	index int // 所属四元组在 arena 中的下标，回收后为 -1
 }
 
 type edgeID uint32
@@ -18,31 +18,8 @@ const (
 	quad      edgeID = 0x00000003
 )
 
-func (e *QuadEdge) Init() {
-	e1 := New(e.pool)
-	e2 := New(e.pool)
-	e3 := New(e.pool)
-
-	e1.Init2(e)
-	e2.Init2(e1)
-	e3.Init2(e2)
-
-	e.qprev = e3
-	e3.qnext = e
-
-	e.next = e
-	e1.next = e3
-	e2.next = e2
-	e3.next = e1
-}
-
-func (e *QuadEdge) Init2(ed *QuadEdge) {
-	e.qprev = ed
-	ed.qnext = e
-}
-
-func (e *QuadEdge) Pool() *Pool {
-	return e.pool
+func (e *QuadEdge) Arena() *EdgeArena {
+	return e.arena
 }
 
 func (e *QuadEdge) Rot() *QuadEdge {
@@ -147,14 +124,6 @@ func (e *QuadEdge) SetLeftFace(f *DelaunayTriangle) {
 	e.lface = f
 }
 
-func (e *QuadEdge) clear() {
-	if e != nil && e.pool != nil && e.index > 0 {
-		e.pool.clear(e.index)
-		e.pool = nil
-		e.index = -1
-	}
-}
-
 func (e *QuadEdge) SetEndPoints(org [2]float64, dest [2]float64) {
 	e.data = org
 	sym := e.Sym()
@@ -163,33 +132,20 @@ func (e *QuadEdge) SetEndPoints(org [2]float64, dest [2]float64) {
 	}
 }
 
+// 回收 e 所在的四元组，四条边的连接关系一并清除
 func (e *QuadEdge) recycle() {
-	e.clear()
-}
-
-// 优化后的回收方法
-func (e *QuadEdge) RecycleNext() {
-	if e == nil || e.index <= 0 {
+	if e == nil || e.arena == nil || e.index < 0 {
 		return
 	}
-
-	// 收集四元组的所有边
-	edges := []*QuadEdge{e, e.qnext, e.qnext.qnext, e.qprev}
-
-	// 清除所有边的连接关系
-	for _, edge := range edges {
-		if edge != nil {
-			edge.qnext = nil
-			edge.qprev = nil
-			edge.next = nil
-			edge.lface = nil
-		}
-	}
-
-	// 批量回收边
-	for _, edge := range edges {
-		if edge != nil {
-			edge.recycle()
-		}
+	arena, index := e.arena, e.index
+	q := arena.quads.At(index)
+	for i := range q {
+		q[i].qnext = nil
+		q[i].qprev = nil
+		q[i].next = nil
+		q[i].lface = nil
+		q[i].arena = nil
+		q[i].index = -1
 	}
+	arena.release(index)
 }
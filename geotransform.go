@@ -0,0 +1,68 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+
+	vec2d "github.com/flywave/go3d/float64/vec2"
+)
+
+// 仿射地理变换，与 GDAL 约定一致:
+//
+//	X = GT[0] + col*GT[1] + row*GT[2]
+//	Y = GT[3] + col*GT[4] + row*GT[5]
+//
+// (GT[0], GT[3]) 为第 0 行第 0 列像元的左上角 (像元为面) 或中心 (像元为点)
+// 北向上栅格 GT[5] < 0，南向上栅格 GT[5] > 0，GT[2]/GT[4] 为旋转项
+type GeoTransform [6]float64
+
+// 无旋转的地理变换，sizeY 带符号，北向上时为负
+func NewGeoTransform(originX, originY, sizeX, sizeY float64) GeoTransform {
+	return GeoTransform{originX, sizeX, 0, originY, 0, sizeY}
+}
+
+// 像素坐标 (col, row) 对应的地理坐标
+func (gt GeoTransform) Apply(col, row float64) (float64, float64) {
+	return gt[0] + col*gt[1] + row*gt[2], gt[3] + col*gt[4] + row*gt[5]
+}
+
+// 逆变换，将地理坐标映射为像素坐标
+func (gt GeoTransform) Invert() (GeoTransform, error) {
+	det := gt[1]*gt[5] - gt[2]*gt[4]
+	if det == 0 || math.IsNaN(det) {
+		return GeoTransform{}, fmt.Errorf("geotransform is not invertible: %v", gt)
+	}
+	return GeoTransform{
+		(gt[2]*gt[3] - gt[0]*gt[5]) / det,
+		gt[5] / det,
+		-gt[2] / det,
+		(gt[0]*gt[4] - gt[1]*gt[3]) / det,
+		-gt[4] / det,
+		gt[1] / det,
+	}, nil
+}
+
+// 是否无旋转项
+func (gt GeoTransform) IsAxisAligned() bool {
+	return gt[2] == 0 && gt[4] == 0
+}
+
+// 原点平移到 (col, row)，像元放大 factor 倍
+func (gt GeoTransform) window(col, row, factor float64) GeoTransform {
+	x, y := gt.Apply(col, row)
+	return GeoTransform{x, gt[1] * factor, gt[2] * factor, y, gt[4] * factor, gt[5] * factor}
+}
+
+// 像素范围 [0, cols] x [0, rows] 四个角点的外包矩形
+func (gt GeoTransform) extent(cols, rows int) vec2d.Rect {
+	rect := vec2d.Rect{
+		Min: vec2d.T{math.MaxFloat64, math.MaxFloat64},
+		Max: vec2d.T{-math.MaxFloat64, -math.MaxFloat64},
+	}
+	for _, c := range [4][2]float64{{0, 0}, {float64(cols), 0}, {0, float64(rows)}, {float64(cols), float64(rows)}} {
+		x, y := gt.Apply(c[0], c[1])
+		rect.Min = vec2d.T{math.Min(rect.Min[0], x), math.Min(rect.Min[1], y)}
+		rect.Max = vec2d.T{math.Max(rect.Max[0], x), math.Max(rect.Max[1], y)}
+	}
+	return rect
+}
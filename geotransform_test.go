@@ -0,0 +1,113 @@
+package tin
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	vec2d "github.com/flywave/go3d/float64/vec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoTransformInvert(t *testing.T) {
+	gt := GeoTransform{100, 2, 0.5, 200, 0.25, -3}
+	inv, err := gt.Invert()
+	assert.NoError(t, err)
+	x, y := gt.Apply(7.5, 3.25)
+	c, r := inv.Apply(x, y)
+	assert.InDelta(t, 7.5, c, 1e-9)
+	assert.InDelta(t, 3.25, r, 1e-9)
+
+	_, err = GeoTransform{0, 1, 2, 0, 2, 4}.Invert()
+	assert.Error(t, err)
+}
+
+func TestRasterGeoTransform(t *testing.T) {
+	// 北向上，与 SetXYPos 一致
+	r := NewRasterDouble(4, 5, math.NaN())
+	r.SetXYPos(100, 200, 10)
+	assert.Equal(t, NewGeoTransform(100, 240, 10, -10), r.GeoTransform())
+	assert.Equal(t, [4]float64{240, 200, 150, 100}, r.Bounds)
+	assert.Equal(t, 105.0, r.ColToX(0))
+	assert.Equal(t, 235.0, r.RowToY(0))
+	assert.Equal(t, 2, r.XToCol(129))
+	assert.Equal(t, 3, r.YToRow(201))
+
+	// 南向上且像元非正方形：第 0 行位于南侧
+	s := NewRasterDouble(4, 5, math.NaN())
+	s.SetGeoTransform(NewGeoTransform(100, 200, 2, 1))
+	assert.Equal(t, 2.0, s.CellSizeX())
+	assert.Equal(t, 1.0, s.CellSizeY())
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{100, 200}, Max: vec2d.T{110, 204}}, s.Extent())
+	assert.Equal(t, [4]float64{204, 200, 110, 100}, s.Bounds)
+	assert.Equal(t, 200.5, s.RowToY(0))
+	assert.Equal(t, 203.5, s.RowToY(3))
+	assert.Equal(t, 109.0, s.ColToX(4))
+	assert.Equal(t, 0, s.YToRow(200.9))
+	assert.Equal(t, 3, s.YToRow(203.1))
+	assert.Equal(t, 4, s.XToCol(108.5))
+	col, row := s.geoToPixel(103, 202.5)
+	assert.InDelta(t, 1.0, col, 1e-12)
+	assert.InDelta(t, 2.0, row, 1e-12)
+
+	// 像元为点：原点为第一个像元中心
+	p := NewRasterDouble(3, 3, math.NaN())
+	p.SetGeoTransform(NewGeoTransform(0, 20, 10, -10))
+	p.SetPixelIsPoint(true)
+	assert.Equal(t, 0.0, p.ColToX(0))
+	assert.Equal(t, 20.0, p.RowToY(0))
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{-5, -5}, Max: vec2d.T{25, 25}}, p.Extent())
+	assert.Equal(t, 0, p.XToCol(4.9))
+	assert.Equal(t, 1, p.XToCol(5.1))
+
+	// 旋转
+	q := NewRasterDouble(2, 2, math.NaN())
+	q.SetGeoTransform(GeoTransform{0, 1, -1, 0, 1, 1})
+	x, y := q.CellCenter(1, 0)
+	assert.InDelta(t, -1.0, x, 1e-12)
+	assert.InDelta(t, 2.0, y, 1e-12)
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{-2, 0}, Max: vec2d.T{2, 4}}, q.Extent())
+}
+
+func TestRasterGeoTransformWindow(t *testing.T) {
+	// 南向上栅格的裁剪与拼接
+	src := NewRasterDouble(6, 6, -9999)
+	for i := range src.Data {
+		src.Data[i] = float64(i)
+	}
+	src.SetGeoTransform(NewGeoTransform(0, 0, 1, 1))
+
+	c, err := src.Crop(vec2d.Rect{Min: vec2d.T{2, 1}, Max: vec2d.T{4, 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{2, 2}, c.Size)
+	assert.Equal(t, src.Value(1, 2), c.Value(0, 0))
+	assert.Equal(t, NewGeoTransform(2, 1, 1, 1), c.GeoTransform())
+
+	north, _ := src.Crop(vec2d.Rect{Min: vec2d.T{0, 3}, Max: vec2d.T{6, 6}})
+	south, _ := src.Crop(vec2d.Rect{Min: vec2d.T{0, 0}, Max: vec2d.T{6, 3}})
+	m, err := Mosaic(north, south)
+	assert.NoError(t, err)
+	assert.Equal(t, src.GeoTransform(), m.GeoTransform())
+	assert.Equal(t, src.Data, m.Data)
+
+	// 概览与分块文件保留像元为点的地理参考
+	src.SetPixelIsPoint(true)
+	o, err := Downsample2x(src, OverviewAverage)
+	assert.NoError(t, err)
+	assert.False(t, o.PixelIsPoint())
+	assert.Equal(t, src.Extent(), o.Extent())
+
+	path := filepath.Join(t.TempDir(), "dem.tinr")
+	assert.NoError(t, WriteTiledRaster(path, src, 4))
+	tr, err := OpenTiledRaster[float64](path)
+	assert.NoError(t, err)
+	defer tr.Close()
+	assert.Equal(t, src.GeoTransform(), tr.GeoTransform())
+	assert.True(t, tr.PixelIsPoint())
+	win, err := tr.ReadWindow(1, 2, 3, 3)
+	assert.NoError(t, err)
+	x, y := win.CellCenter(0, 0)
+	sx, sy := src.CellCenter(1, 2)
+	assert.Equal(t, sx, x)
+	assert.Equal(t, sy, y)
+}
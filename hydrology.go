@@ -0,0 +1,306 @@
+package tin
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// D8 流向编码 (与 ESRI 一致)，0 表示无出流 (洼地、平地或出水口)
+const (
+	FlowEast      int32 = 1
+	FlowSouthEast int32 = 2
+	FlowSouth     int32 = 4
+	FlowSouthWest int32 = 8
+	FlowWest      int32 = 16
+	FlowNorthWest int32 = 32
+	FlowNorth     int32 = 64
+	FlowNorthEast int32 = 128
+
+	FlowNone   int32 = 0
+	FlowNoData int32 = -1
+)
+
+// 八邻域偏移 (行, 列) 及对应流向编码，行号向下递增
+var d8Offsets = [8]struct {
+	dr, dc int
+	dir    int32
+}{
+	{0, 1, FlowEast},
+	{1, 1, FlowSouthEast},
+	{1, 0, FlowSouth},
+	{1, -1, FlowSouthWest},
+	{0, -1, FlowWest},
+	{-1, -1, FlowNorthWest},
+	{-1, 0, FlowNorth},
+	{-1, 1, FlowNorthEast},
+}
+
+func d8Offset(dir int32) (dr, dc int, ok bool) {
+	for _, o := range d8Offsets {
+		if o.dir == dir {
+			return o.dr, o.dc, true
+		}
+	}
+	return 0, 0, false
+}
+
+type floodCell struct {
+	z   float64
+	seq int
+	idx int
+}
+
+// 按高程升序的优先队列，高程相同时先入先出
+type floodQueue []floodCell
+
+func (q floodQueue) Len() int { return len(q) }
+func (q floodQueue) Less(i, j int) bool {
+	if q[i].z != q[j].z {
+		return q[i].z < q[j].z
+	}
+	return q[i].seq < q[j].seq
+}
+func (q floodQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *floodQueue) Push(x interface{}) { *q = append(*q, x.(floodCell)) }
+func (q *floodQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	c := old[n-1]
+	*q = old[:n-1]
+	return c
+}
+
+// 优先洪泛法 (Priority-Flood) 填充洼地，返回新栅格
+// 栅格边界及与 NoData 相邻的单元视为出水口；epsilon > 0 时在填平区域内
+// 按 epsilon 递增构造微小坡度，保证每个单元都有向出水口的下坡路径
+func FillDepressions(dem *RasterDouble, epsilon float64) (*RasterDouble, error) {
+	if dem == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	rows, cols := dem.Rows(), dem.Cols()
+	noDataValue := dem.NoData
+	src := dem.DataSlice()
+
+	filled := dem.Clone()
+	out := filled.DataSlice()
+
+	valid := func(r, c int) bool {
+		return r >= 0 && r < rows && c >= 0 && c < cols && !isNoData(src[r*cols+c], noDataValue)
+	}
+
+	closed := make([]bool, rows*cols)
+	q := &floodQueue{}
+	seq := 0
+	push := func(idx int, z float64) {
+		closed[idx] = true
+		heap.Push(q, floodCell{z: z, seq: seq, idx: idx})
+		seq++
+	}
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if !valid(r, c) {
+				continue
+			}
+			edge := false
+			for _, o := range d8Offsets {
+				if !valid(r+o.dr, c+o.dc) {
+					edge = true
+					break
+				}
+			}
+			if edge {
+				push(r*cols+c, src[r*cols+c])
+			}
+		}
+	}
+
+	for q.Len() > 0 {
+		cell := heap.Pop(q).(floodCell)
+		r, c := cell.idx/cols, cell.idx%cols
+		for _, o := range d8Offsets {
+			nr, nc := r+o.dr, c+o.dc
+			if !valid(nr, nc) {
+				continue
+			}
+			n := nr*cols + nc
+			if closed[n] {
+				continue
+			}
+			if out[n] < out[cell.idx]+epsilon {
+				out[n] = out[cell.idx] + epsilon
+			}
+			push(n, out[n])
+		}
+	}
+	return filled, nil
+}
+
+// 计算 D8 流向：每个单元流向按地面距离折算后下降最大的相邻单元
+// 正交方向距离取 X/Y 像元尺寸，对角方向取两者的斜边长
+// 没有更低邻域的单元为 FlowNone，NoData 单元为 FlowNoData
+func FlowDirectionD8(dem *RasterDouble) (*RasterInt, error) {
+	if dem == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	rows, cols := dem.Rows(), dem.Cols()
+	noDataValue := dem.NoData
+
+	dir := NewRasterInt(rows, cols, FlowNoData)
+	dir.copyGeoReference(&dem.RasterGrid)
+
+	sx, sy := dem.CellSizeX(), dem.CellSizeY()
+	if sx <= 0 || sy <= 0 {
+		sx, sy = 1, 1
+	}
+	var dist [len(d8Offsets)]float64
+	for i, o := range d8Offsets {
+		dist[i] = math.Hypot(float64(o.dc)*sx, float64(o.dr)*sy)
+	}
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			z := dem.Value(r, c)
+			if isNoData(z, noDataValue) {
+				continue
+			}
+			best, bestDir := 0.0, FlowNone
+			for i, o := range d8Offsets {
+				nr, nc := r+o.dr, c+o.dc
+				if nr < 0 || nr >= rows || nc < 0 || nc >= cols {
+					continue
+				}
+				nz := dem.Value(nr, nc)
+				if isNoData(nz, noDataValue) {
+					continue
+				}
+				drop := (z - nz) / dist[i]
+				if drop > best {
+					best, bestDir = drop, o.dir
+				}
+			}
+			dir.SetValue(r, c, bestDir)
+		}
+	}
+	return dir, nil
+}
+
+// 由 D8 流向计算汇流累积量，即流经每个单元的上游单元数 (含自身)
+// NoData 单元为 NaN
+func FlowAccumulation(dir *RasterInt) (*RasterDouble, error) {
+	if dir == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	rows, cols := dir.Rows(), dir.Cols()
+	d := dir.DataSlice()
+
+	acc := NewRasterDouble(rows, cols, math.NaN())
+	acc.copyGeoReference(&dir.RasterGrid)
+	a := acc.DataSlice()
+
+	downstream := func(idx int) int {
+		dr, dc, ok := d8Offset(d[idx])
+		if !ok {
+			return -1
+		}
+		r, c := idx/cols+dr, idx%cols+dc
+		if r < 0 || r >= rows || c < 0 || c >= cols || d[r*cols+c] == FlowNoData {
+			return -1
+		}
+		return r*cols + c
+	}
+
+	// 按拓扑顺序自上游向下游累加
+	indegree := make([]int, rows*cols)
+	for i := range d {
+		if d[i] == FlowNoData {
+			continue
+		}
+		a[i] = 1
+		if n := downstream(i); n >= 0 {
+			indegree[n]++
+		}
+	}
+	var stack []int
+	for i := range d {
+		if d[i] != FlowNoData && indegree[i] == 0 {
+			stack = append(stack, i)
+		}
+	}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n := downstream(i); n >= 0 {
+			a[n] += a[i]
+			indegree[n]--
+			if indegree[n] == 0 {
+				stack = append(stack, n)
+			}
+		}
+	}
+	return acc, nil
+}
+
+// 提取汇流累积量不小于 threshold 的河网
+// 每条折线从河源或汇合点开始，沿流向到下一个汇合点或出水口结束，
+// 顶点为单元中心的地理坐标，高程取自 dem，可作为 TIN 生成的特征线
+func ExtractStreams(dem *RasterDouble, dir *RasterInt, acc *RasterDouble, threshold float64) ([][]Vertex, error) {
+	if dem == nil || dir == nil || acc == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	rows, cols := dir.Rows(), dir.Cols()
+	if dem.Rows() != rows || dem.Cols() != cols || acc.Rows() != rows || acc.Cols() != cols {
+		return nil, fmt.Errorf("raster size mismatch")
+	}
+	d := dir.DataSlice()
+	a := acc.DataSlice()
+
+	stream := func(idx int) bool {
+		return !math.IsNaN(a[idx]) && a[idx] >= threshold
+	}
+	downstream := func(idx int) int {
+		dr, dc, ok := d8Offset(d[idx])
+		if !ok {
+			return -1
+		}
+		r, c := idx/cols+dr, idx%cols+dc
+		if r < 0 || r >= rows || c < 0 || c >= cols || !stream(r*cols+c) {
+			return -1
+		}
+		return r*cols + c
+	}
+
+	inflow := make([]int, rows*cols)
+	for i := range d {
+		if stream(i) {
+			if n := downstream(i); n >= 0 {
+				inflow[n]++
+			}
+		}
+	}
+
+	vertex := func(idx int) Vertex {
+		x, y := dem.pixelToGeo(float64(idx%cols), float64(idx/cols))
+		return Vertex{x, y, dem.Value(idx/cols, idx%cols)}
+	}
+
+	var lines [][]Vertex
+	for i := range d {
+		// 河源 (无上游河道) 与汇合点 (多条上游河道) 作为折线起点
+		if !stream(i) || inflow[i] == 1 {
+			continue
+		}
+		line := []Vertex{vertex(i)}
+		for cur := downstream(i); cur >= 0; cur = downstream(cur) {
+			line = append(line, vertex(cur))
+			if inflow[cur] > 1 {
+				break
+			}
+		}
+		if len(line) > 1 {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,162 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+// 9x9 向南倾斜的 V 形谷地，谷底为第 4 列，(4, 4) 处有一个洼地
+func createValleyRaster() *RasterDouble {
+	raster := NewRasterDouble(9, 9, math.NaN())
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			raster.SetValue(r, c, 2*math.Abs(float64(c-4))+float64(8-r))
+		}
+	}
+	raster.SetValue(4, 4, -5)
+	raster.SetValue(0, 0, math.NaN())
+	raster.SetXYPos(0, 0, 1)
+	return raster
+}
+
+func TestFillDepressions(t *testing.T) {
+	dem := createValleyRaster()
+
+	filled, err := FillDepressions(dem, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := filled.Value(4, 4); v != 3 {
+		t.Errorf("洼地应填充到溢出点高程 3, 实际 %.4f", v)
+	}
+	if dem.Value(4, 4) != -5 {
+		t.Error("不应修改输入栅格")
+	}
+	for r := 0; r < 9; r++ {
+		for c := 0; c < 9; c++ {
+			if r == 4 && c == 4 {
+				continue
+			}
+			a, b := dem.Value(r, c), filled.Value(r, c)
+			if !(math.IsNaN(a) && math.IsNaN(b)) && a != b {
+				t.Errorf("非洼地单元 (%d, %d) 被修改: %.3f -> %.3f", r, c, a, b)
+			}
+		}
+	}
+
+	filled, _ = FillDepressions(dem, 0.01)
+	if v := filled.Value(4, 4); math.Abs(v-3.01) > 1e-12 {
+		t.Errorf("epsilon 填充结果错误: %.4f", v)
+	}
+}
+
+func TestFlowDirectionAndAccumulation(t *testing.T) {
+	filled, _ := FillDepressions(createValleyRaster(), 0.01)
+	dir, err := FlowDirectionD8(filled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		row, col int
+		expected int32
+	}{
+		{0, 0, FlowNoData},
+		{0, 4, FlowSouth},
+		{4, 4, FlowSouth},
+		{8, 0, FlowEast},
+		{8, 8, FlowWest},
+		{3, 3, FlowSouthEast},
+		{8, 4, FlowNone},
+	}
+	for _, c := range cases {
+		if v := dir.Value(c.row, c.col); v != c.expected {
+			t.Errorf("单元 (%d, %d) 流向错误: 预期 %d, 实际 %d", c.row, c.col, c.expected, v)
+		}
+	}
+
+	acc, err := FlowAccumulation(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := acc.Value(8, 4); v != 80 {
+		t.Errorf("出水口汇流量应为全部有效单元数 80, 实际 %.0f", v)
+	}
+	if !math.IsNaN(acc.Value(0, 0)) {
+		t.Error("NoData 单元汇流量应为 NaN")
+	}
+	if v := acc.Value(0, 8); v != 1 {
+		t.Errorf("山脊单元汇流量应为 1, 实际 %.0f", v)
+	}
+
+	t.Run("Streams", func(t *testing.T) {
+		lines, err := ExtractStreams(filled, dir, acc, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 1 {
+			t.Fatalf("预期 1 条河道, 实际 %d", len(lines))
+		}
+		line := lines[0]
+		for i, v := range line {
+			if v[0] != 4.5 {
+				t.Errorf("河道应沿谷底: %v", v)
+			}
+			if i > 0 && v[2] >= line[i-1][2] {
+				t.Errorf("河道高程应递减: %v", line)
+			}
+		}
+		if last := line[len(line)-1]; last[1] != 0.5 {
+			t.Errorf("河道应在出水口结束: %v", last)
+		}
+
+		// 低阈值时河道在汇合点处断开
+		lines, _ = ExtractStreams(filled, dir, acc, 3)
+		if len(lines) < 2 {
+			t.Fatalf("预期多条河道, 实际 %d", len(lines))
+		}
+		starts := make(map[[2]float64]bool)
+		for _, l := range lines {
+			starts[[2]float64{l[0][0], l[0][1]}] = true
+		}
+		for _, l := range lines {
+			end := l[len(l)-1]
+			if !(end[0] == 4.5 && end[1] == 0.5) && !starts[[2]float64{end[0], end[1]}] {
+				t.Errorf("河道终点既不是出水口也不是汇合点: %v", end)
+			}
+		}
+	})
+}
+
+// 非正方形像元按 X/Y 地面距离计算坡降
+func TestFlowDirectionNonSquare(t *testing.T) {
+	dem := NewRasterDouble(3, 3, math.NaN())
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			dem.SetValue(r, c, 20)
+		}
+	}
+	dem.SetValue(1, 1, 10)
+	dem.SetValue(1, 2, 8)   // 东
+	dem.SetValue(2, 1, 7)   // 南
+	dem.SetValue(2, 2, 6.5) // 东南
+
+	for _, c := range []struct {
+		sx, sy   float64
+		expected int32
+	}{
+		{1, 1, FlowSouth},
+		{1, 4, FlowEast},
+		{4, 1, FlowSouth},
+		{1, 0.5, FlowSouth},
+		{0.25, 1, FlowEast},
+	} {
+		dem.SetXYPosCellSize(0, 0, c.sx, c.sy)
+		dir, err := FlowDirectionD8(dem)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := dir.Value(1, 1); d != c.expected {
+			t.Errorf("像元 %vx%v: 流向预期 %d, 实际 %d", c.sx, c.sy, c.expected, d)
+		}
+	}
+}
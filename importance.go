@@ -0,0 +1,91 @@
+package tin
+
+import "math"
+
+// 候选点重要性函数，返回值越大越优先插入
+// 只影响插入顺序，MaxError 仍按垂直误差判断三角形是否需要继续细分
+type ImportanceFunc func(s *ImportanceSample) float64
+
+// 扫描三角形时传给重要性函数的栅格单元信息，同一三角形内复用，不应保留引用
+type ImportanceSample struct {
+	Row   int
+	Col   int
+	Z     float64 // 栅格高程
+	Error float64 // 栅格高程减去三角形平面插值高程
+	Plane Plane   // 三角形所在平面，像素坐标
+	Area  float64 // 三角形地面面积
+
+	mesh *ZemlyaMesh
+}
+
+// 像元 X/Y 方向地面长度
+func (s *ImportanceSample) CellSize() (float64, float64) {
+	grid := s.mesh.grid()
+	return grid.CellSizeX(), grid.CellSizeY()
+}
+
+// 三角形平面在地面坐标下的坡度分量
+func (s *ImportanceSample) PlaneGradient() (float64, float64) {
+	sx, sy := s.CellSize()
+	return s.Plane[0] / sx, s.Plane[1] / sy
+}
+
+// 栅格在该单元处的坡度分量，中心差分，邻域无效时退化为单侧差分
+func (s *ImportanceSample) Gradient() (float64, float64) {
+	sx, sy := s.CellSize()
+	return s.derivative(0, 1) / sx, s.derivative(1, 0) / sy
+}
+
+// 沿 (dr, dc) 方向每像元的高程变化
+func (s *ImportanceSample) derivative(dr, dc int) float64 {
+	grid := s.mesh.grid()
+	src := s.mesh.elevation()
+	at := func(r, c int) float64 {
+		if r < 0 || c < 0 || r >= grid.Rows() || c >= grid.Cols() {
+			return math.NaN()
+		}
+		return src.Elevation(r, c)
+	}
+	z0 := at(s.Row, s.Col)
+	prev := at(s.Row-dr, s.Col-dc)
+	next := at(s.Row+dr, s.Col+dc)
+	switch {
+	case !math.IsNaN(prev) && !math.IsNaN(next):
+		return (next - prev) / 2
+	case !math.IsNaN(next) && !math.IsNaN(z0):
+		return next - z0
+	case !math.IsNaN(prev) && !math.IsNaN(z0):
+		return z0 - prev
+	}
+	return 0
+}
+
+// 垂直误差，默认的重要性
+func VerticalImportance(s *ImportanceSample) float64 {
+	return math.Abs(s.Error)
+}
+
+// 垂直误差除以该单元沿地表坡面的长度 (像元边长*sqrt(1+坡度²))
+// 同样的垂直误差在平缓处优先于陡坡处插入，即降低陡峭地形的优先级；
+// 只改变插入顺序，不随像元大小缩放 MaxError
+func SurfaceLengthImportance(s *ImportanceSample) float64 {
+	sx, sy := s.CellSize()
+	spacing := math.Sqrt(sx * sy)
+	gx, gy := s.Gradient()
+	return math.Abs(s.Error) / math.Hypot(spacing, spacing*math.Hypot(gx, gy))
+}
+
+// 垂直误差按三角形平面与地表法向夹角 (弧度) 加权，优先插入坡度突变处的点
+func NormalDeviationImportance(s *ImportanceSample) float64 {
+	px, py := s.PlaneGradient()
+	gx, gy := s.Gradient()
+	// 法向 (-dz/dx, -dz/dy, 1) 的夹角
+	dot := px*gx + py*gy + 1
+	cos := dot / (math.Sqrt(px*px+py*py+1) * math.Sqrt(gx*gx+gy*gy+1))
+	return math.Abs(s.Error) * (1 + math.Acos(math.Min(1, cos)))
+}
+
+// 垂直误差乘以三角形地面面积，优先细分大三角形
+func AreaImportance(s *ImportanceSample) float64 {
+	return math.Abs(s.Error) * s.Area
+}
@@ -0,0 +1,137 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+// 行列方向线性增长的斜坡，像元 2x8
+func createSlopeMesh() *ZemlyaMesh {
+	const rows, cols = 9, 9
+	data := make([]float64, rows*cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			data[r*cols+c] = 2*float64(c) + 3*float64(r)
+		}
+	}
+	raster := NewRasterDoubleWithData(rows, cols, data)
+	raster.SetXYPosCellSize(0, 0, 2, 8)
+
+	z := NewZemlyaMesh(&GeoConfig{})
+	z.LoadRaster(raster)
+	return z
+}
+
+func TestImportanceSample(t *testing.T) {
+	z := createSlopeMesh()
+	s := &ImportanceSample{Row: 4, Col: 4, Error: -0.5, Plane: Plane{2, 3, 0}, Area: 10, mesh: z}
+
+	if sx, sy := s.CellSize(); sx != 2 || sy != 8 {
+		t.Errorf("像元尺寸错误: %v, %v", sx, sy)
+	}
+	if gx, gy := s.Gradient(); math.Abs(gx-1) > 1e-12 || math.Abs(gy-0.375) > 1e-12 {
+		t.Errorf("栅格坡度错误: %v, %v", gx, gy)
+	}
+	// 边界单元退化为单侧差分
+	edge := &ImportanceSample{Row: 0, Col: 8, mesh: z}
+	if gx, gy := edge.Gradient(); math.Abs(gx-1) > 1e-12 || math.Abs(gy-0.375) > 1e-12 {
+		t.Errorf("边界坡度错误: %v, %v", gx, gy)
+	}
+
+	if v := VerticalImportance(s); v != 0.5 {
+		t.Errorf("VerticalImportance = %v, 期望 0.5", v)
+	}
+	// 坡面长度 4*sqrt(1+1+0.375²)
+	if v := SurfaceLengthImportance(s); math.Abs(v-0.5/(4*math.Sqrt(2.140625))) > 1e-12 {
+		t.Errorf("SurfaceLengthImportance = %v, 期望 %v", v, 0.5/(4*math.Sqrt(2.140625)))
+	}
+	// 同一栅格上 SurfaceLengthImportance 与 VerticalImportance 之比随坡度变化，不是常数
+	flatMesh := NewZemlyaMesh(&GeoConfig{})
+	flatMesh.LoadRaster(NewRasterDoubleWithData(3, 3, make([]float64, 9)))
+	onFlat := &ImportanceSample{Row: 1, Col: 1, Error: -0.5, mesh: flatMesh}
+	if ratio := SurfaceLengthImportance(onFlat) / SurfaceLengthImportance(s); math.Abs(ratio-math.Sqrt(2.140625)*4) > 1e-9 {
+		t.Errorf("平缓处与坡面处之比 = %v", ratio)
+	}
+	if v := AreaImportance(s); v != 5 {
+		t.Errorf("AreaImportance = %v, 期望 5", v)
+	}
+	// 平面与地表平行时不加权
+	if v := NormalDeviationImportance(s); math.Abs(v-0.5) > 1e-6 {
+		t.Errorf("平行平面 NormalDeviationImportance = %v, 期望 0.5", v)
+	}
+	flat := *s
+	flat.Plane = Plane{0, 0, 0}
+	if v := NormalDeviationImportance(&flat); v <= 0.5 {
+		t.Errorf("水平平面 NormalDeviationImportance = %v, 应大于 0.5", v)
+	}
+}
+
+func TestZemlyaMeshImportance(t *testing.T) {
+	funcs := map[string]ImportanceFunc{
+		"Default":         nil,
+		"Vertical":        VerticalImportance,
+		"SurfaceLength":   SurfaceLengthImportance,
+		"NormalDeviation": NormalDeviationImportance,
+		"Area":            AreaImportance,
+	}
+	for name, fn := range funcs {
+		t.Run(name, func(t *testing.T) {
+			z := NewZemlyaMesh(&GeoConfig{})
+			z.Importance = fn
+			z.LoadRaster(createWaveRaster())
+			z.GreedyInsert(0.1)
+			m := z.ToMesh()
+
+			// 重要性只影响插入顺序，误差阈值仍按垂直误差满足
+			if z.AchievedError > 0.1+1e-9 {
+				t.Errorf("误差 %.4f 超过阈值 0.1", z.AchievedError)
+			}
+			if !m.CheckTin() {
+				t.Error("生成的网格不是有效TIN")
+			}
+		})
+	}
+
+	t.Run("SurfaceLengthDiffersFromVertical", func(t *testing.T) {
+		meshes := map[string]*ZemlyaMesh{}
+		for name, fn := range map[string]ImportanceFunc{"Vertical": VerticalImportance, "SurfaceLength": SurfaceLengthImportance} {
+			z := NewZemlyaMesh(&GeoConfig{})
+			z.Importance = fn
+			z.LoadRaster(createWaveRaster())
+			z.GreedyInsertWithBudget(0.1, 60, 0)
+			meshes[name] = z
+		}
+		if insertedCellsHash(meshes["Vertical"]) == insertedCellsHash(meshes["SurfaceLength"]) {
+			t.Error("SurfaceLengthImportance 应按坡度改变插入顺序，网格不应与 VerticalImportance 相同")
+		}
+	})
+
+	t.Run("Order", func(t *testing.T) {
+		// 自定义重要性优先插入左半部分，预算不足时顶点应集中在左侧
+		raster := createWaveRaster()
+		half := raster.Cols() / 2
+		z := NewZemlyaMesh(&GeoConfig{})
+		z.Importance = func(s *ImportanceSample) float64 {
+			if s.Col < half {
+				return math.Abs(s.Error) + 100
+			}
+			return math.Abs(s.Error)
+		}
+		z.LoadRaster(raster)
+		z.GreedyInsertWithBudget(0.1, 40, 0)
+		m := z.ToMesh()
+
+		left, right := 0, 0
+		for _, v := range m.Vertices {
+			col := raster.XToCol(v[0])
+			if col < half {
+				left++
+			} else if col > half {
+				right++
+			}
+		}
+		if left <= 2*right {
+			t.Errorf("顶点未集中在左侧: 左=%d, 右=%d", left, right)
+		}
+	})
+}
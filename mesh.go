@@ -1,10 +1,13 @@
 package tin
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"sort"
+	"strconv"
 
 	"github.com/flywave/go-geo"
 )
@@ -444,3 +447,55 @@ func (m *Mesh) ExportOBJ(filename string, reproj bool) error {
 
 	return nil
 }
+
+// 三角面属性，Values 与 Mesh.Faces 一一对应
+type FaceAttribute struct {
+	Name   string
+	Values []float64
+}
+
+// 以 ASCII PLY 格式导出顶点、三角面及三角面属性
+// 数值按最短可往返的十进制写出，属性为 double；NaN 属性值写为 nan，无穷写为 inf/-inf
+func (m *Mesh) ExportPLY(w io.Writer, attrs []FaceAttribute) error {
+	if !m.hasDecomposed() {
+		m.GenerateDecomposed()
+	}
+	for _, a := range attrs {
+		if len(a.Values) != len(m.Faces) {
+			return fmt.Errorf("face attribute %s has %d values, expected %d", a.Name, len(a.Values), len(m.Faces))
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "ply\nformat ascii 1.0\nelement vertex %d\nproperty double x\nproperty double y\nproperty double z\n", len(m.Vertices))
+	fmt.Fprintf(bw, "element face %d\nproperty list uchar int vertex_indices\n", len(m.Faces))
+	for _, a := range attrs {
+		fmt.Fprintf(bw, "property double %s\n", a.Name)
+	}
+	bw.WriteString("end_header\n")
+
+	for _, v := range m.Vertices {
+		bw.WriteString(plyFloat(v[0]) + " " + plyFloat(v[1]) + " " + plyFloat(v[2]) + "\n")
+	}
+	for fi, f := range m.Faces {
+		fmt.Fprintf(bw, "3 %d %d %d", f[0], f[1], f[2])
+		for _, a := range attrs {
+			bw.WriteString(" " + plyFloat(a.Values[fi]))
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// 常见 PLY 读取器以 strtod 解析数值，非有限值使用其可识别的写法
+func plyFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "nan"
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
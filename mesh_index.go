@@ -0,0 +1,151 @@
+package tin
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// 基于均匀网格的 Mesh 空间索引，用于按平面坐标查询高程
+// 构建后只读，可被多个 goroutine 并发查询
+type MeshIndex struct {
+	mesh     *Mesh
+	bbox     BBox2d
+	cellSize [2]float64
+	dims     [2]int // 列数, 行数
+	cells    [][]int32
+}
+
+// 平均每个索引单元容纳的三角形数
+const meshIndexFacesPerCell = 2
+
+func NewMeshIndex(mesh *Mesh) *MeshIndex {
+	if !mesh.hasDecomposed() {
+		mesh.GenerateDecomposed()
+	}
+
+	idx := &MeshIndex{
+		mesh: mesh,
+		bbox: BBox2d{math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64},
+	}
+	for _, v := range mesh.Vertices {
+		idx.bbox.add(v[:])
+	}
+
+	// 按长宽比划分索引单元
+	n := math.Max(1, float64(len(mesh.Faces))/meshIndexFacesPerCell)
+	w := math.Max(idx.bbox.Width(), EPS)
+	h := math.Max(idx.bbox.Height(), EPS)
+	cell := math.Sqrt(w * h / n)
+	idx.dims[0] = int(math.Max(1, math.Ceil(w/cell)))
+	idx.dims[1] = int(math.Max(1, math.Ceil(h/cell)))
+	idx.cellSize = [2]float64{w / float64(idx.dims[0]), h / float64(idx.dims[1])}
+	idx.cells = make([][]int32, idx.dims[0]*idx.dims[1])
+
+	for fi, f := range mesh.Faces {
+		tb := BBox2d{math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64}
+		for i := range f {
+			tb.add(mesh.Vertices[f[i]][:])
+		}
+		c0, r0 := idx.cellOf(tb[0], tb[1])
+		c1, r1 := idx.cellOf(tb[2], tb[3])
+		for r := r0; r <= r1; r++ {
+			for c := c0; c <= c1; c++ {
+				k := r*idx.dims[0] + c
+				idx.cells[k] = append(idx.cells[k], int32(fi))
+			}
+		}
+	}
+	return idx
+}
+
+func (idx *MeshIndex) Mesh() *Mesh {
+	return idx.mesh
+}
+
+func (idx *MeshIndex) cellOf(x, y float64) (int, int) {
+	c := int((x - idx.bbox[0]) / idx.cellSize[0])
+	r := int((y - idx.bbox[1]) / idx.cellSize[1])
+	c = MinInt(max(c, 0), idx.dims[0]-1)
+	r = MinInt(max(r, 0), idx.dims[1]-1)
+	return c, r
+}
+
+// 返回与 b 相交的索引单元中的全部三角面 (去重)
+func (idx *MeshIndex) facesInBBox(b BBox2d) []int32 {
+	if !idx.bbox.Intersects(b, EPS) {
+		return nil
+	}
+	c0, r0 := idx.cellOf(b[0], b[1])
+	c1, r1 := idx.cellOf(b[2], b[3])
+	seen := make(map[int32]struct{})
+	var faces []int32
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			for _, fi := range idx.cells[r*idx.dims[0]+c] {
+				if _, ok := seen[fi]; !ok {
+					seen[fi] = struct{}{}
+					faces = append(faces, fi)
+				}
+			}
+		}
+	}
+	return faces
+}
+
+// 平面点 p 相对三角形 abc 的重心坐标，与顶点顺序无关
+func barycentric(a, b, c, p [2]float64) (l0, l1, l2 float64, ok bool) {
+	d := (b[1]-c[1])*(a[0]-c[0]) + (c[0]-b[0])*(a[1]-c[1])
+	if math.Abs(d) < EPS*EPS {
+		return 0, 0, 0, false
+	}
+	l0 = ((b[1]-c[1])*(p[0]-c[0]) + (c[0]-b[0])*(p[1]-c[1])) / d
+	l1 = ((c[1]-a[1])*(p[0]-c[0]) + (a[0]-c[0])*(p[1]-c[1])) / d
+	l2 = 1 - l0 - l1
+	const tol = -1e-9
+	return l0, l1, l2, l0 >= tol && l1 >= tol && l2 >= tol
+}
+
+// 查询 (x, y) 处的插值高程及所在三角面索引，点不在网格内时 ok 为 false
+func (idx *MeshIndex) HeightAt(x, y float64) (z float64, face int, ok bool) {
+	if !idx.bbox.Contains([]float64{x, y}, EPS) {
+		return math.NaN(), -1, false
+	}
+	c, r := idx.cellOf(x, y)
+	p := [2]float64{x, y}
+	for _, fi := range idx.cells[r*idx.dims[0]+c] {
+		f := idx.mesh.Faces[fi]
+		v0, v1, v2 := idx.mesh.Vertices[f[0]], idx.mesh.Vertices[f[1]], idx.mesh.Vertices[f[2]]
+		l0, l1, l2, inside := barycentric(
+			[2]float64{v0[0], v0[1]}, [2]float64{v1[0], v1[1]}, [2]float64{v2[0], v2[1]}, p)
+		if inside {
+			return l0*v0[2] + l1*v1[2] + l2*v2[2], int(fi), true
+		}
+	}
+	return math.NaN(), -1, false
+}
+
+// 批量查询高程，未命中的点高程为 NaN、面索引为 -1
+// concurrency <= 0 时使用 runtime.GOMAXPROCS(0) 个 goroutine
+func (idx *MeshIndex) HeightsAt(points [][2]float64, concurrency int) ([]float64, []int) {
+	heights := make([]float64, len(points))
+	faces := make([]int, len(points))
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	chunk := (len(points) + concurrency - 1) / concurrency
+	var wg sync.WaitGroup
+	for start := 0; start < len(points); start += chunk {
+		end := MinInt(start+chunk, len(points))
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				heights[i], faces[i], _ = idx.HeightAt(points[i][0], points[i][1])
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	return heights, faces
+}
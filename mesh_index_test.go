@@ -0,0 +1,69 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeshIndex(t *testing.T) {
+	t.Run("Plane", func(t *testing.T) {
+		mesh := &Mesh{}
+		mesh.InitFromTriangles([]Triangle{
+			{{0, 0, 0}, {10, 0, 10}, {10, 10, 30}},
+			{{0, 0, 0}, {10, 10, 30}, {0, 10, 20}},
+		})
+		idx := NewMeshIndex(mesh)
+
+		// z = x + 2y
+		cases := [][2]float64{{0, 0}, {5, 5}, {2.5, 7.5}, {10, 10}, {9.99, 0.01}, {0, 10}}
+		for _, p := range cases {
+			z, face, ok := idx.HeightAt(p[0], p[1])
+			if !ok || face < 0 {
+				t.Errorf("点 (%.2f,%.2f) 应在网格内", p[0], p[1])
+				continue
+			}
+			if math.Abs(z-(p[0]+2*p[1])) > 1e-9 {
+				t.Errorf("点 (%.2f,%.2f) 高程错误: 预期 %.3f, 实际 %.3f", p[0], p[1], p[0]+2*p[1], z)
+			}
+		}
+
+		if _, face, ok := idx.HeightAt(11, 5); ok || face != -1 {
+			t.Error("网格外的点应返回 ok=false")
+		}
+	})
+
+	t.Run("ZemlyaMesh", func(t *testing.T) {
+		src := createWaveRaster()
+		z := NewZemlyaMesh(&GeoConfig{})
+		z.LoadRaster(src)
+		z.GreedyInsert(0.2)
+		mesh := z.ToMesh()
+		idx := NewMeshIndex(mesh)
+
+		raster := NewRasterDouble(src.Rows(), src.Cols(), math.NaN())
+		raster.copyGeoReference(&src.RasterGrid)
+		RasterizeMeshTo(mesh, raster)
+
+		var points [][2]float64
+		for row := 0; row < src.Rows(); row++ {
+			for col := 0; col < src.Cols(); col++ {
+				points = append(points, [2]float64{src.ColToX(col), src.RowToY(row)})
+			}
+		}
+
+		heights, faces := idx.HeightsAt(points, 4)
+		for i, p := range points {
+			row, col := i/src.Cols(), i%src.Cols()
+			if faces[i] < 0 {
+				t.Fatalf("点 (%.2f,%.2f) 未命中", p[0], p[1])
+			}
+			if math.Abs(heights[i]-raster.Value(row, col)) > 1e-6 {
+				t.Errorf("点 (%.2f,%.2f) 高程与栅格化结果不一致: %.6f != %.6f",
+					p[0], p[1], heights[i], raster.Value(row, col))
+			}
+			if z, face, _ := idx.HeightAt(p[0], p[1]); z != heights[i] || face != faces[i] {
+				t.Errorf("批量查询与单点查询结果不一致")
+			}
+		}
+	})
+}
@@ -1,6 +1,12 @@
 package tin
 
-import "testing"
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
 
 // 测试函数
 func TestMesh(t *testing.T) {
@@ -136,3 +142,52 @@ func TestMesh(t *testing.T) {
 		}
 	})
 }
+
+func TestMeshExportPLY(t *testing.T) {
+	mesh := createGridMesh(1, func(x, y float64) float64 { return x })
+	d := mesh.TerrainDerivatives(1)
+	classes, _ := ClassifySlope(d.FaceSlope, []float64{30})
+
+	var buf bytes.Buffer
+	if err := mesh.ExportPLY(&buf, d.FaceAttributes(classes)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"element vertex 4\n", "element face 2\n", "property double slope\n",
+		"property double aspect\n", "property double slope_class\n", "end_header\n"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("PLY 头部缺少 %q", s)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(out[strings.Index(out, "end_header\n")+len("end_header\n"):]), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("PLY 数据行数错误: %d", len(lines))
+	}
+	if f := strings.Fields(lines[4]); len(f) != 8 || f[0] != "3" || f[4] != "45" || f[5] != "270" || f[7] != "1" {
+		t.Errorf("三角面数据错误: %q", lines[4])
+	}
+
+	if err := mesh.ExportPLY(&buf, []FaceAttribute{{Name: "bad", Values: []float64{1}}}); err == nil {
+		t.Error("属性数量不符应返回错误")
+	}
+	// 经纬度坐标与微小属性值应完整保留，NaN 属性写为 nan
+	geoMesh := &Mesh{}
+	geoMesh.InitFromTriangles([]Triangle{{{118.0512345678901, 36.81671234567, 12.25}, {118.0512445678901, 36.81671234567, 12.5}, {118.0512345678901, 36.81672234567, 12.75}}})
+	buf.Reset()
+	if err := geoMesh.ExportPLY(&buf, []FaceAttribute{{Name: "a", Values: []float64{1.5e-9}}, {Name: "b", Values: []float64{math.NaN()}}}); err != nil {
+		t.Fatal(err)
+	}
+	out = buf.String()
+	lines = strings.Split(strings.TrimSpace(out[strings.Index(out, "end_header\n")+len("end_header\n"):]), "\n")
+	for i, v := range geoMesh.Vertices {
+		f := strings.Fields(lines[i])
+		for j := range v {
+			if x, err := strconv.ParseFloat(f[j], 64); err != nil || x != v[j] {
+				t.Errorf("顶点 %d 坐标 %d 精度丢失: %q != %v", i, j, f[j], v[j])
+			}
+		}
+	}
+	if f := strings.Fields(lines[3]); len(f) != 6 || f[4] != "1.5e-09" || f[5] != "nan" {
+		t.Errorf("属性数据错误: %q", lines[3])
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package tin
+
+import (
+	"fmt"
+	"os"
+)
+
+// 不支持内存映射的平台，调用方退回按需读取文件
+func mmapFile(f *os.File, size int64) ([]byte, error) {
+	return nil, fmt.Errorf("mmap not supported")
+}
+
+func munmapFile(data []byte) error {
+	return nil
+}
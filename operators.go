@@ -35,12 +35,7 @@ func Connect(a, b *QuadEdge) *QuadEdge {
 		return nil
 	}
 
-	e := New(a.pool)
-	if e == nil {
-		return nil
-	}
-
-	e.Init()
+	e := a.arena.New()
 	e.SetOrig(a.Dest())
 	e.SetDest(b.Orig())
 
@@ -0,0 +1,111 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+)
+
+// 概览降采样方法
+type OverviewMethod int
+
+const (
+	OverviewAverage OverviewMethod = iota // 有效值均值
+	OverviewMin                           // 保留最小值 (如谷底)
+	OverviewMax                           // 保留最大值 (如山脊、峰顶)
+)
+
+// 按 2x2 像元块降采样，分辨率加倍，左上角对齐
+// 奇数行列时最后一行/列的像元块不完整，NoData 不参与计算，块内全为 NoData 时结果为 NoData
+func Downsample2x(src *RasterDouble, method OverviewMethod) (*RasterDouble, error) {
+	if src == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	if method < OverviewAverage || method > OverviewMax {
+		return nil, fmt.Errorf("unknown overview method: %d", method)
+	}
+	rows, cols := src.Rows(), src.Cols()
+	noDataValue := src.NoData
+	outRows, outCols := (rows+1)/2, (cols+1)/2
+
+	dst := NewRasterDouble(outRows, outCols, noDataValue)
+	dst.setWindowGeoReference(&src.RasterGrid, 0, 0, 2)
+
+	for r := 0; r < outRows; r++ {
+		for c := 0; c < outCols; c++ {
+			var acc float64
+			count := 0
+			for _, v := range [4]float64{
+				src.Value(2*r, 2*c),
+				src.Value(2*r, MinInt(2*c+1, cols-1)),
+				src.Value(MinInt(2*r+1, rows-1), 2*c),
+				src.Value(MinInt(2*r+1, rows-1), MinInt(2*c+1, cols-1)),
+			} {
+				if isNoData(v, noDataValue) {
+					continue
+				}
+				switch {
+				case count == 0:
+					acc = v
+				case method == OverviewMin:
+					acc = math.Min(acc, v)
+				case method == OverviewMax:
+					acc = math.Max(acc, v)
+				default:
+					acc += v
+				}
+				count++
+			}
+			if count == 0 {
+				continue
+			}
+			if method == OverviewAverage {
+				acc /= float64(count)
+			}
+			dst.SetValue(r, c, acc)
+		}
+	}
+	return dst, nil
+}
+
+// 栅格金字塔，Levels[0] 为原始栅格，Levels[i] 分辨率为原始的 2^i 倍
+type RasterPyramid struct {
+	Levels []*RasterDouble
+}
+
+// 逐级 2 倍降采样直到行列数均不大于 minSize
+func BuildPyramid(src *RasterDouble, method OverviewMethod, minSize int) (*RasterPyramid, error) {
+	if src == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	if src.minCellSize() <= 0 {
+		return nil, fmt.Errorf("invalid cellsize: %.6f", src.minCellSize())
+	}
+	minSize = max(1, minSize)
+
+	p := &RasterPyramid{Levels: []*RasterDouble{src}}
+	for level := src; level.Rows() > minSize || level.Cols() > minSize; {
+		next, err := Downsample2x(level, method)
+		if err != nil {
+			return nil, err
+		}
+		p.Levels = append(p.Levels, next)
+		level = next
+	}
+	return p, nil
+}
+
+// 返回分辨率不粗于 resolution 的最粗一级，resolution <= 0 时返回原始栅格
+// 像元非正方形时按较大的边长比较
+func (p *RasterPyramid) Select(resolution float64) *RasterDouble {
+	best := p.Levels[0]
+	if resolution <= 0 {
+		return best
+	}
+	for _, level := range p.Levels[1:] {
+		if level.maxCellSize() > resolution*(1+EPS) {
+			break
+		}
+		best = level
+	}
+	return best
+}
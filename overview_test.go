@@ -0,0 +1,83 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDownsample2x(t *testing.T) {
+	src := NewRasterDoubleWithData(3, 5, []float64{
+		1, 2, 3, 4, 5,
+		6, 7, 8, 9, 10,
+		11, 12, 13, 14, 15,
+	})
+	src.NoData = math.NaN()
+	src.SetXYPos(100, 200, 1)
+	src.SetValue(0, 0, math.NaN())
+
+	avg, err := Downsample2x(src, OverviewAverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avg.Rows() != 2 || avg.Cols() != 3 || avg.CellSize() != 2 {
+		t.Fatalf("概览尺寸错误: %dx%d cellsize=%.2f", avg.Rows(), avg.Cols(), avg.CellSize())
+	}
+	if v := avg.Value(0, 0); v != (2.0+6+7)/3 {
+		t.Errorf("均值应跳过 NoData: %.4f", v)
+	}
+	if v := avg.Value(1, 2); v != 15 {
+		t.Errorf("不完整像元块: %.4f", v)
+	}
+
+	// 左上角对齐：概览像元 (0, 0) 中心位于源像元 (0.5, 0.5) 处
+	x0, y0 := avg.pixelToGeo(0, 0)
+	x1, y1 := src.pixelToGeo(0.5, 0.5)
+	if math.Abs(x0-x1) > EPS || math.Abs(y0-y1) > EPS {
+		t.Errorf("概览未左上角对齐: (%.2f, %.2f) != (%.2f, %.2f)", x0, y0, x1, y1)
+	}
+
+	lo, _ := Downsample2x(src, OverviewMin)
+	hi, _ := Downsample2x(src, OverviewMax)
+	if lo.Value(0, 1) != 3 || hi.Value(0, 1) != 9 || lo.Value(1, 0) != 11 || hi.Value(1, 0) != 12 {
+		t.Errorf("最小/最大值概览错误: %v %v", lo.DataSlice(), hi.DataSlice())
+	}
+
+	empty := NewRasterDouble(2, 2, math.NaN())
+	empty.SetXYPos(0, 0, 1)
+	if out, _ := Downsample2x(empty, OverviewAverage); !math.IsNaN(out.Value(0, 0)) {
+		t.Error("全为 NoData 的像元块应为 NoData")
+	}
+}
+
+func TestRasterPyramid(t *testing.T) {
+	pyramid, err := BuildPyramid(createWaveRaster(), OverviewAverage, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 33 -> 17 -> 9 -> 5 -> 3
+	if len(pyramid.Levels) != 5 {
+		t.Fatalf("层数错误: %d", len(pyramid.Levels))
+	}
+	for i, level := range pyramid.Levels {
+		if want := math.Pow(2, float64(i)); level.CellSize() != want {
+			t.Errorf("第 %d 级分辨率 %.2f, 期望 %.2f", i, level.CellSize(), want)
+		}
+	}
+
+	tests := []struct {
+		resolution float64
+		level      int
+	}{
+		{0, 0},
+		{0.5, 0},
+		{1, 0},
+		{3, 1},
+		{4, 2},
+		{1000, 4},
+	}
+	for _, tt := range tests {
+		if got := pyramid.Select(tt.resolution); got != pyramid.Levels[tt.level] {
+			t.Errorf("分辨率 %.2f 应选第 %d 级, 实际分辨率 %.2f", tt.resolution, tt.level, got.CellSize())
+		}
+	}
+}
@@ -11,42 +11,108 @@ var (
 	NAN_DATA = Data{data: [2]float64{math.NaN(), math.NaN()}, lface: nil}
 )
 
-type Pool struct {
-	Values []interface{}
-	New    func() interface{}
+// 每块记录数，必须为 2 的幂
+const (
+	arenaChunkBits = 8
+	arenaChunkSize = 1 << arenaChunkBits
+)
+
+// 按块分配的类型化对象池，记录以下标寻址
+// 块一经分配不再移动，记录指针在回收前始终有效；回收的下标进入空闲链表供下次分配复用
+type Arena[T any] struct {
+	chunks [][]T
+	free   []int
+	size   int // 已分配过的下标数
 }
 
-func NewPool(newFn func() interface{}) *Pool {
-	return &Pool{New: newFn}
+func NewArena[T any]() *Arena[T] {
+	return &Arena[T]{}
 }
 
-func (p *Pool) clear(index int) {
-	p.Values[index] = nil
+// 分配一条清零的记录，返回下标与指针
+func (a *Arena[T]) alloc() (int, *T) {
+	var index int
+	if n := len(a.free); n > 0 {
+		index = a.free[n-1]
+		a.free = a.free[:n-1]
+	} else {
+		index = a.size
+		if index>>arenaChunkBits == len(a.chunks) {
+			a.chunks = append(a.chunks, make([]T, arenaChunkSize))
+		}
+		a.size++
+	}
+	// 空闲链表与重置后复用的记录可能残留旧值
+	item := a.At(index)
+	var zero T
+	*item = zero
+	return index, item
 }
 
-func New(p *Pool) *QuadEdge {
-	qe := &QuadEdge{pool: p, index: len(p.Values)}
-	p.Values = append(p.Values, qe)
-	return qe
+// 回收下标对应的记录，调用方需保证不再持有该记录的指针
+func (a *Arena[T]) release(index int) {
+	a.free = append(a.free, index)
 }
 
-func (p *Pool) Len() int {
-	return len(p.Values)
+// 下标对应的记录
+func (a *Arena[T]) At(index int) *T {
+	return &a.chunks[index>>arenaChunkBits][index&(arenaChunkSize-1)]
 }
 
-func (p *Pool) Get() interface{} {
-	// 从后往前扫描，跳过nil值
-	for i := len(p.Values) - 1; i >= 0; i-- {
-		item := p.Values[i]
-		if item != nil {
-			// 移除找到的有效元素并返回
-			p.Values = append(p.Values[:i], p.Values[i+1:]...)
-			return item
-		}
+// 有效记录数
+func (a *Arena[T]) Len() int {
+	return a.size - len(a.free)
+}
+
+// 回收全部记录，保留已分配的块供复用
+func (a *Arena[T]) Reset() {
+	a.size = 0
+	a.free = a.free[:0]
+}
+
+// 四元组的四条边连续存放，依次为 e、Rot、Sym、Tor
+type edgeQuad [4]QuadEdge
+
+// 四元边对象池，一次分配一个四元组
+type EdgeArena struct {
+	quads Arena[edgeQuad]
+}
+
+func NewEdgeArena() *EdgeArena {
+	return &EdgeArena{}
+}
+
+// 分配一个初始化好的孤立四元组，返回其基础边
+func (a *EdgeArena) New() *QuadEdge {
+	index, q := a.quads.alloc()
+	for i := range q {
+		q[i].arena = a
+		q[i].index = index
+		q[i].qnext = &q[(i+1)&3]
+		q[i].qprev = &q[(i+3)&3]
 	}
-	return p.New()
+	q[0].next = &q[0]
+	q[1].next = &q[3]
+	q[2].next = &q[2]
+	q[3].next = &q[1]
+	return &q[0]
+}
+
+// 第 index 个四元组的基础边
+func (a *EdgeArena) Edge(index int) *QuadEdge {
+	return &a.quads.At(index)[0]
+}
+
+// 有效边数，每个四元组计四条
+func (a *EdgeArena) Len() int {
+	return 4 * a.quads.Len()
+}
+
+// 回收全部四元组
+func (a *EdgeArena) Reset() {
+	a.quads.Reset()
 }
 
-func (p *Pool) Put(item interface{}) {
-	p.Values = append(p.Values, item)
+func (a *EdgeArena) release(index int) {
+	a.quads.release(index)
 }
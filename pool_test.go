@@ -2,106 +2,64 @@ package tin
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestPool(t *testing.T) {
+func TestArena(t *testing.T) {
 	t.Run("基本功能测试", func(t *testing.T) {
-		// 创建一个计数器，用于跟踪创建的对象数量
-		counter := 0
-		pool := NewPool(func() interface{} {
-			counter++
-			val := counter
-			return &val // 返回独立变量的指针
-		})
-
-		// 初始状态验证
-		assert.Equal(t, 0, pool.Len(), "初始池长度应为0")
-
-		// 第一次获取对象
-		obj1 := pool.Get()
-		assert.Equal(t, 1, counter, "应创建第一个对象")
-		assert.Equal(t, 1, *(obj1.(*int)), "对象值应为1")
-
-		// 第二次获取对象
-		obj2 := pool.Get()
-		assert.Equal(t, 2, counter, "应创建第二个对象")
-		assert.Equal(t, 2, *(obj2.(*int)), "对象值应为2")
-
-		// 将对象放回池中
-		pool.Put(obj1)
-		assert.Equal(t, 1, pool.Len(), "放回一个对象后池长度应为1")
-
-		pool.Put(obj2)
-		assert.Equal(t, 2, pool.Len(), "放回两个对象后池长度应为2")
-
-		// 再次获取对象 - 应重用之前放回的对象
-		obj3 := pool.Get()
-		assert.Equal(t, 2, counter, "不应创建新对象")
-		assert.Equal(t, 2, *(obj3.(*int)), "应获取最后放回的对象")
-		assert.Equal(t, 1, pool.Len(), "获取一个对象后池长度应为1")
-
-		obj4 := pool.Get()
-		assert.Equal(t, 2, counter, "不应创建新对象")
-		assert.Equal(t, 1, *(obj4.(*int)), "应获取第一个放回的对象")
-		assert.Equal(t, 0, pool.Len(), "池应为空")
+		arena := NewArena[int]()
+		assert.Equal(t, 0, arena.Len(), "初始长度应为0")
+
+		i0, p0 := arena.alloc()
+		i1, p1 := arena.alloc()
+		*p0, *p1 = 10, 11
+		assert.Equal(t, 0, i0)
+		assert.Equal(t, 1, i1)
+		assert.Equal(t, 2, arena.Len())
+		assert.Equal(t, 11, *arena.At(1), "应按下标取回记录")
 	})
 
-	t.Run("池为空时创建新对象", func(t *testing.T) {
-		counter := 0
-		pool := NewPool(func() interface{} {
-			counter++
-			return counter
-		})
-
-		// 池为空时获取对象
-		obj := pool.Get()
-		assert.Equal(t, 1, obj.(int), "应创建新对象")
-		assert.Equal(t, 0, pool.Len(), "池长度应为0")
-	})
-
-	t.Run("清空池", func(t *testing.T) {
-		pool := NewPool(func() interface{} {
-			return "new"
-		})
-
-		// 添加多个对象
-		pool.Put("item1")
-		pool.Put("item2")
-		pool.Put("item3")
-		assert.Equal(t, 3, pool.Len(), "池长度应为3")
-
-		// 清空特定索引
-		pool.clear(1)
-		assert.Equal(t, 3, pool.Len(), "池长度不变")
-		assert.Nil(t, pool.Values[1], "索引1应为nil")
-
-		// 获取对象时应跳过nil
-		item1 := pool.Get()
-		assert.Equal(t, "item3", item1, "应获取最后一个对象")
+	t.Run("回收后复用并清零", func(t *testing.T) {
+		arena := NewArena[int]()
+		arena.alloc()
+		i1, p1 := arena.alloc()
+		*p1 = 42
 
-		item2 := pool.Get()
-		assert.Equal(t, "item1", item2, "应获取第一个对象") // 恢复原始断言
+		arena.release(i1)
+		assert.Equal(t, 1, arena.Len(), "回收后长度应减1")
 
-		item3 := pool.Get()
-		assert.Equal(t, "new", item3, "应创建新对象")
+		i2, p2 := arena.alloc()
+		assert.Equal(t, i1, i2, "应复用空闲下标")
+		assert.Same(t, p1, p2, "应复用同一记录")
+		assert.Equal(t, 0, *p2, "复用的记录应清零")
 	})
 
-	t.Run("不同类型对象", func(t *testing.T) {
-		pool := NewPool(func() interface{} {
-			return struct{ value int }{value: 42}
-		})
-
-		// 获取新对象
-		obj := pool.Get()
-		assert.Equal(t, 42, obj.(struct{ value int }).value, "对象值应为42")
+	t.Run("扩容时指针不变", func(t *testing.T) {
+		arena := NewArena[int]()
+		_, first := arena.alloc()
+		*first = 7
+		for i := 0; i < 3*arenaChunkSize; i++ {
+			arena.alloc()
+		}
+		assert.Same(t, first, arena.At(0), "新增块不应移动已有记录")
+		assert.Equal(t, 7, *first)
+		assert.Equal(t, 3*arenaChunkSize+1, arena.Len())
+	})
 
-		// 放回后重用
-		pool.Put(obj)
-		reused := pool.Get()
-		assert.Equal(t, 42, reused.(struct{ value int }).value, "应重用对象")
+	t.Run("重置", func(t *testing.T) {
+		arena := NewArena[int]()
+		_, p := arena.alloc()
+		*p = 5
+		arena.alloc()
+		arena.Reset()
+		assert.Equal(t, 0, arena.Len())
+
+		_, q := arena.alloc()
+		assert.Same(t, p, q, "重置后应复用已分配的块")
+		assert.Equal(t, 0, *q, "复用的记录应清零")
 	})
 
 	t.Run("NAN_DATA 测试", func(t *testing.T) {
@@ -110,16 +68,113 @@ func TestPool(t *testing.T) {
 		assert.True(t, math.IsNaN(nanData.data[1]), "NAN_DATA 的第二个元素应为 NaN")
 		assert.Nil(t, nanData.lface, "NAN_DATA 的 lface 应为 nil")
 	})
+}
+
+func TestEdgeArena(t *testing.T) {
+	t.Run("四元组初始化", func(t *testing.T) {
+		arena := NewEdgeArena()
+		e := arena.New()
+		assert.Equal(t, 4, arena.Len(), "一个四元组计四条边")
+		assert.Same(t, e, arena.Edge(0))
+		assert.Same(t, arena, e.Arena())
+
+		assert.Same(t, e, e.Rot().Rot().Rot().Rot(), "Rot 四次应回到自身")
+		assert.Same(t, e, e.Sym().Sym())
+		assert.Same(t, e.Tor(), e.Rot().Sym())
+		assert.Same(t, e, e.OrigNext(), "孤立边的 Onext 为自身")
+		assert.Same(t, e.Sym(), e.Sym().OrigNext())
+		assert.Same(t, e.Tor(), e.Rot().OrigNext(), "孤立边左右面相同")
+	})
+
+	t.Run("回收", func(t *testing.T) {
+		arena := NewEdgeArena()
+		e := arena.New()
+		f := arena.New()
+		sym := e.Sym()
+
+		sym.recycle()
+		assert.Equal(t, 4, arena.Len(), "应回收整个四元组")
+		assert.Equal(t, -1, e.index)
+		assert.Nil(t, e.Rot())
+		sym.recycle()
+		assert.Equal(t, 4, arena.Len(), "重复回收不应生效")
+
+		g := arena.New()
+		assert.Same(t, e, g, "应复用回收的四元组")
+		assert.Same(t, g, g.Sym().Sym())
+		assert.NotSame(t, f, g)
+	})
 
-	t.Run("New 函数测试", func(t *testing.T) {
-		pool := NewPool(func() interface{} {
-			return "default"
-		})
+	t.Run("重建网格回收旧记录", func(t *testing.T) {
+		mesh := &DelaunayMesh{scanTriangle: func(*DelaunayTriangle) {}}
+		mesh.InitMeshFromBBox(BBox2d{0, 0, 10, 10})
+		mesh.Insert([2]float64{3, 4}, nil)
+		mesh.Insert([2]float64{7, 2}, nil)
+		edges, triangles := mesh.QuadEdges.Len(), mesh.Triangles.Len()
+
+		mesh.InitMeshFromBBox(BBox2d{0, 0, 10, 10})
+		assert.Equal(t, 20, mesh.QuadEdges.Len())
+		assert.Equal(t, 2, mesh.Triangles.Len())
+
+		mesh.Insert([2]float64{3, 4}, nil)
+		mesh.Insert([2]float64{7, 2}, nil)
+		assert.Equal(t, edges, mesh.QuadEdges.Len())
+		assert.Equal(t, triangles, mesh.Triangles.Len())
+	})
+}
+
+func faceCount(m *DelaunayMesh) int {
+	n := 0
+	for f := m.firstFace; f != nil; f = f.GetLink() {
+		n++
+	}
+	return n
+}
+
+func TestDelaunayFaceRelease(t *testing.T) {
+	t.Run("删除面回收记录", func(t *testing.T) {
+		mesh := &DelaunayMesh{scanTriangle: func(*DelaunayTriangle) {}}
+		mesh.InitMeshFromBBox(BBox2d{0, 0, 10, 10})
+		mesh.Insert([2]float64{3, 4}, nil)
+		n := mesh.Triangles.Len()
+
+		// 分别删除链表中间与表头的面
+		middle := mesh.firstFace.GetLink()
+		next := middle.GetLink()
+		mesh.deleteFace(middle)
+		assert.Same(t, next, mesh.firstFace.GetLink(), "应从链表中摘除")
+		head := mesh.firstFace
+		mesh.deleteFace(head)
+		assert.Same(t, next, mesh.firstFace)
+		assert.Nil(t, mesh.firstFace.prev)
+		assert.Equal(t, n-2, mesh.Triangles.Len())
+		assert.Equal(t, n-2, faceCount(mesh))
+
+		f := mesh.makeFace(mesh.startingQuadEdge)
+		assert.Same(t, head, f, "应复用回收的记录")
+		assert.Equal(t, n-1, mesh.Triangles.Len())
+	})
 
-		// 使用 New 函数创建 QuadEdge
-		qe := New(pool)
-		assert.NotNil(t, qe, "New 函数应返回非 nil 对象")
-		assert.Equal(t, 0, qe.index, "新对象的索引应为0")
-		assert.Equal(t, 1, pool.Len(), "池长度应为1")
+	t.Run("插入后记录数与面数一致", func(t *testing.T) {
+		// 网格点大量落在已有边与边界上
+		mesh := &DelaunayMesh{scanTriangle: func(*DelaunayTriangle) {}}
+		mesh.InitMeshFromBBox(BBox2d{0, 0, 10, 10})
+		rng := rand.New(rand.NewSource(1))
+		var pts [][2]float64
+		for x := 0; x <= 10; x++ {
+			for y := 0; y <= 10; y++ {
+				pts = append(pts, [2]float64{float64(x), float64(y)})
+			}
+		}
+		for i := 0; i < 100; i++ {
+			pts = append(pts, [2]float64{rng.Float64() * 10, rng.Float64() * 10})
+		}
+		rng.Shuffle(len(pts), func(i, j int) { pts[i], pts[j] = pts[j], pts[i] })
+		for _, p := range pts {
+			mesh.Insert(p, nil)
+			if n := faceCount(mesh); n != mesh.Triangles.Len() {
+				t.Fatalf("插入 %v 后面数 %d 与记录数 %d 不一致", p, n, mesh.Triangles.Len())
+			}
+		}
 	})
 }
@@ -0,0 +1,158 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 剖面采样点，Distance 为沿折线的平面距离
+// 点不在网格内时 Z 为 NaN
+type ProfileSample struct {
+	Distance float64
+	X        float64
+	Y        float64
+	Z        float64
+}
+
+type Profile struct {
+	Samples  []ProfileSample
+	Length2D float64
+	Length3D float64 // 仅累计两端都在网格内的区间
+	MinZ     float64
+	MaxZ     float64
+}
+
+// 沿折线提取 Mesh 高程剖面，包含折线顶点及与每条三角形边的交点
+// interval > 0 时另外按固定间距采样
+func MeshProfile(mesh *Mesh, line [][2]float64, interval float64) (*Profile, error) {
+	return NewMeshIndex(mesh).Profile(line, interval)
+}
+
+func (idx *MeshIndex) Profile(line [][2]float64, interval float64) (*Profile, error) {
+	if len(line) < 2 {
+		return nil, fmt.Errorf("polyline needs at least 2 points, got %d", len(line))
+	}
+
+	// 折线顶点及其与三角形边的交点
+	var samples []ProfileSample
+	dist := 0.0
+	for i := range line {
+		if i > 0 {
+			a, b := line[i-1], line[i]
+			segLen := math.Hypot(b[0]-a[0], b[1]-a[1])
+			samples = append(samples, idx.segmentCrossings(a, b, dist, segLen)...)
+			dist += segLen
+		}
+		samples = append(samples, idx.profileSample(line[i], dist))
+	}
+
+	// 固定间距采样
+	if interval > 0 {
+		seg := 0
+		segStart := 0.0
+		for k := 1; float64(k)*interval < dist; k++ {
+			d := float64(k) * interval
+			segLen := math.Hypot(line[seg+1][0]-line[seg][0], line[seg+1][1]-line[seg][1])
+			for d > segStart+segLen && seg+2 < len(line) {
+				segStart += segLen
+				seg++
+				segLen = math.Hypot(line[seg+1][0]-line[seg][0], line[seg+1][1]-line[seg][1])
+			}
+			t := 0.0
+			if segLen > 0 {
+				t = (d - segStart) / segLen
+			}
+			p := [2]float64{
+				line[seg][0] + t*(line[seg+1][0]-line[seg][0]),
+				line[seg][1] + t*(line[seg+1][1]-line[seg][1]),
+			}
+			samples = append(samples, idx.profileSample(p, d))
+		}
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Distance < samples[j].Distance
+	})
+
+	profile := &Profile{Length2D: dist, MinZ: math.NaN(), MaxZ: math.NaN()}
+	for _, s := range samples {
+		if n := len(profile.Samples); n > 0 && math.Abs(profile.Samples[n-1].Distance-s.Distance) < EPS {
+			// 重复位置保留有效高程
+			if math.IsNaN(profile.Samples[n-1].Z) {
+				profile.Samples[n-1] = s
+			}
+			continue
+		}
+		profile.Samples = append(profile.Samples, s)
+	}
+
+	for i, s := range profile.Samples {
+		if math.IsNaN(s.Z) {
+			continue
+		}
+		if math.IsNaN(profile.MinZ) || s.Z < profile.MinZ {
+			profile.MinZ = s.Z
+		}
+		if math.IsNaN(profile.MaxZ) || s.Z > profile.MaxZ {
+			profile.MaxZ = s.Z
+		}
+		if i > 0 && !math.IsNaN(profile.Samples[i-1].Z) {
+			prev := profile.Samples[i-1]
+			profile.Length3D += math.Hypot(s.Distance-prev.Distance, s.Z-prev.Z)
+		}
+	}
+	return profile, nil
+}
+
+func (idx *MeshIndex) profileSample(p [2]float64, d float64) ProfileSample {
+	z, _, _ := idx.HeightAt(p[0], p[1])
+	return ProfileSample{Distance: d, X: p[0], Y: p[1], Z: z}
+}
+
+// 线段 ab 与三角形边的全部交点，start 为 a 点沿折线的距离
+func (idx *MeshIndex) segmentCrossings(a, b [2]float64, start, segLen float64) []ProfileSample {
+	if segLen < EPS {
+		return nil
+	}
+	sb := BBox2d{math.Min(a[0], b[0]), math.Min(a[1], b[1]), math.Max(a[0], b[0]), math.Max(a[1], b[1])}
+
+	var out []ProfileSample
+	for _, fi := range idx.facesInBBox(sb) {
+		f := idx.mesh.Faces[fi]
+		for i := 0; i < 3; i++ {
+			p := idx.mesh.Vertices[f[i]]
+			q := idx.mesh.Vertices[f[(i+1)%3]]
+			t, s, ok := segmentIntersection(a, b, [2]float64{p[0], p[1]}, [2]float64{q[0], q[1]})
+			if !ok {
+				continue
+			}
+			out = append(out, ProfileSample{
+				Distance: start + t*segLen,
+				X:        a[0] + t*(b[0]-a[0]),
+				Y:        a[1] + t*(b[1]-a[1]),
+				Z:        p[2] + s*(q[2]-p[2]),
+			})
+		}
+	}
+	return out
+}
+
+// 线段 ab 与 pq 的交点参数，t 沿 ab，s 沿 pq，平行或不相交时 ok 为 false
+func segmentIntersection(a, b, p, q [2]float64) (t, s float64, ok bool) {
+	r := [2]float64{b[0] - a[0], b[1] - a[1]}
+	e := [2]float64{q[0] - p[0], q[1] - p[1]}
+	denom := r[0]*e[1] - r[1]*e[0]
+	// 容差相对两线段长度之积，即夹角正弦小于 EPS 视为平行，经纬度下的短线段也能求交
+	if math.Abs(denom) <= EPS*math.Hypot(r[0], r[1])*math.Hypot(e[0], e[1]) {
+		return 0, 0, false
+	}
+	ap := [2]float64{p[0] - a[0], p[1] - a[1]}
+	t = (ap[0]*e[1] - ap[1]*e[0]) / denom
+	s = (ap[0]*r[1] - ap[1]*r[0]) / denom
+	const tol = 1e-12
+	if t < -tol || t > 1+tol || s < -tol || s > 1+tol {
+		return 0, 0, false
+	}
+	return Max(0, Min(1, t)), Max(0, Min(1, s)), true
+}
@@ -0,0 +1,132 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeshProfile(t *testing.T) {
+	// 2x2 个单元、8 个三角形的网格，中心隆起
+	var tris []Triangle
+	h := func(x, y float64) float64 {
+		if x == 1 && y == 1 {
+			return 4
+		}
+		return 0
+	}
+	for y := 0.0; y < 2; y++ {
+		for x := 0.0; x < 2; x++ {
+			a := Vertex{x, y, h(x, y)}
+			b := Vertex{x + 1, y, h(x+1, y)}
+			c := Vertex{x + 1, y + 1, h(x+1, y+1)}
+			d := Vertex{x, y + 1, h(x, y+1)}
+			tris = append(tris, Triangle{a, b, c}, Triangle{a, c, d})
+		}
+	}
+	mesh := &Mesh{}
+	mesh.InitFromTriangles(tris)
+
+	t.Run("EdgeCrossings", func(t *testing.T) {
+		// 水平穿过 y=0.5，依次经过 x=0, 0.5(对角线), 1, 1.5(对角线), 2
+		p, err := MeshProfile(mesh, [][2]float64{{0, 0.5}, {2, 0.5}}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []ProfileSample{
+			{0, 0, 0.5, 0}, {0.5, 0.5, 0.5, 2}, {1, 1, 0.5, 2}, {1.5, 1.5, 0.5, 0}, {2, 2, 0.5, 0},
+		}
+		if len(p.Samples) != len(expected) {
+			t.Fatalf("采样点数错误: 预期 %d, 实际 %d (%v)", len(expected), len(p.Samples), p.Samples)
+		}
+		for i, e := range expected {
+			s := p.Samples[i]
+			if math.Abs(s.Distance-e.Distance) > 1e-9 || math.Abs(s.X-e.X) > 1e-9 ||
+				math.Abs(s.Y-e.Y) > 1e-9 || math.Abs(s.Z-e.Z) > 1e-9 {
+				t.Errorf("采样点 %d 错误: 预期 %v, 实际 %v", i, e, s)
+			}
+		}
+		if p.Length2D != 2 {
+			t.Errorf("平面长度错误: %.4f", p.Length2D)
+		}
+		expected3D := 2*math.Hypot(0.5, 2) + 0.5 + 0.5
+		if math.Abs(p.Length3D-expected3D) > 1e-9 {
+			t.Errorf("三维长度错误: 预期 %.6f, 实际 %.6f", expected3D, p.Length3D)
+		}
+		if p.MinZ != 0 || p.MaxZ != 2 {
+			t.Errorf("高程范围错误: [%.2f, %.2f]", p.MinZ, p.MaxZ)
+		}
+	})
+
+	t.Run("IntervalAndPolyline", func(t *testing.T) {
+		line := [][2]float64{{0, 0}, {1, 1}, {2, 1}, {3, 1}}
+		p, err := MeshProfile(mesh, line, 0.25)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(p.Length2D-(math.Sqrt2+2)) > 1e-9 {
+			t.Errorf("平面长度错误: %.6f", p.Length2D)
+		}
+		if p.MaxZ != 4 {
+			t.Errorf("最高点应为 4, 实际 %.2f", p.MaxZ)
+		}
+		for i := 1; i < len(p.Samples); i++ {
+			if p.Samples[i].Distance <= p.Samples[i-1].Distance {
+				t.Fatalf("采样点未按距离排序: %v", p.Samples)
+			}
+		}
+		// 网格外的部分高程为 NaN
+		last := p.Samples[len(p.Samples)-1]
+		if last.X != 3 || !math.IsNaN(last.Z) {
+			t.Errorf("网格外终点应为 NaN: %v", last)
+		}
+		for _, s := range p.Samples {
+			if s.X <= 2 && math.IsNaN(s.Z) {
+				t.Errorf("网格内的点缺少高程: %v", s)
+			}
+		}
+	})
+
+	if _, err := MeshProfile(mesh, [][2]float64{{0, 0}}, 0); err == nil {
+		t.Error("单点折线应返回错误")
+	}
+}
+
+// 经纬度坐标下约 1 米长的线段，叉积远小于 EPS
+func TestSegmentIntersectionGeographic(t *testing.T) {
+	const d = 1e-5
+	a := [2]float64{118.05, 36.8167}
+	b := [2]float64{a[0] + d, a[1]}
+	p := [2]float64{a[0] + d/2, a[1] - d/2}
+	q := [2]float64{a[0] + d/2, a[1] + d/2}
+
+	ts, ss, ok := segmentIntersection(a, b, p, q)
+	if !ok {
+		t.Fatal("短线段的交点被误判为平行")
+	}
+	if math.Abs(ts-0.5) > 1e-6 || math.Abs(ss-0.5) > 1e-6 {
+		t.Errorf("交点参数错误: t=%v, s=%v", ts, ss)
+	}
+
+	// 平行线段仍应排除
+	if _, _, ok := segmentIntersection(a, b, p, [2]float64{p[0] + d, p[1]}); ok {
+		t.Error("平行线段不应相交")
+	}
+
+	// 同尺度网格上的剖面应经过中间的对角线
+	tris := []Triangle{
+		{{a[0], a[1], 0}, {a[0] + d, a[1], 0}, {a[0] + d, a[1] + d, 2}},
+		{{a[0], a[1], 0}, {a[0] + d, a[1] + d, 2}, {a[0], a[1] + d, 0}},
+	}
+	mesh := &Mesh{}
+	mesh.InitFromTriangles(tris)
+	prof, err := MeshProfile(mesh, [][2]float64{{a[0], a[1] + d/2}, {a[0] + d, a[1] + d/2}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prof.Samples) != 3 {
+		t.Fatalf("采样点数错误: 预期 3, 实际 %d (%v)", len(prof.Samples), prof.Samples)
+	}
+	if mid := prof.Samples[1]; math.Abs(mid.X-(a[0]+d/2)) > 1e-12 || math.Abs(mid.Z-1) > 1e-6 {
+		t.Errorf("对角线交点错误: %v", mid)
+	}
+}
@@ -1,6 +1,10 @@
 package tin
 
-import "math"
+import (
+	"math"
+
+	vec2d "github.com/flywave/go3d/float64/vec2"
+)
 
 type RasterType int
 
@@ -17,350 +21,425 @@ const (
 	RASTER_DATA_TYPE_FLOAT64 = 9
 )
 
+// 栅格像元类型，与 RASTER_DATA_TYPE_* 一一对应
+type RasterElement interface {
+	int8 | uint8 | int16 | uint16 | int32 | uint32 | int64 | uint64 | float32 | float64
+}
+
+// 像元类型 T 对应的 RASTER_DATA_TYPE_* 编码
+func RasterDataType[T RasterElement]() int {
+	var v T
+	switch any(v).(type) {
+	case int8:
+		return RASTER_DATA_TYPE_INT8
+	case uint8:
+		return RASTER_DATA_TYPE_UINT8
+	case int16:
+		return RASTER_DATA_TYPE_INT16
+	case uint16:
+		return RASTER_DATA_TYPE_UINT16
+	case int32:
+		return RASTER_DATA_TYPE_INT32
+	case uint32:
+		return RASTER_DATA_TYPE_UINT32
+	case int64:
+		return RASTER_DATA_TYPE_INT64
+	case uint64:
+		return RASTER_DATA_TYPE_UINT64
+	case float32:
+		return RASTER_DATA_TYPE_FLOAT32
+	}
+	return RASTER_DATA_TYPE_FLOAT64
+}
+
+// 像元类型 T 的默认无效值：有符号整数取最小值，无符号整数取 0，浮点数取 NaN
+func defaultNoData[T RasterElement]() T {
+	var v T
+	switch p := any(&v).(type) {
+	case *int8:
+		*p = math.MinInt8
+	case *int16:
+		*p = math.MinInt16
+	case *int32:
+		*p = math.MinInt32
+	case *int64:
+		*p = math.MinInt64
+	case *float32:
+		*p = float32(math.NaN())
+	case *float64:
+		*p = math.NaN()
+	}
+	return v
+}
+
+// 像元类型 T 的最大值
+func maxElement[T RasterElement]() T {
+	var v T
+	switch p := any(&v).(type) {
+	case *int8:
+		*p = math.MaxInt8
+	case *uint8:
+		*p = math.MaxUint8
+	case *int16:
+		*p = math.MaxInt16
+	case *uint16:
+		*p = math.MaxUint16
+	case *int32:
+		*p = math.MaxInt32
+	case *uint32:
+		*p = math.MaxUint32
+	case *int64:
+		*p = math.MaxInt64
+	case *uint64:
+		*p = math.MaxUint64
+	case *float32:
+		*p = math.MaxFloat32
+	case *float64:
+		*p = math.MaxFloat64
+	}
+	return v
+}
+
+// 像元类型 T 可表示的取值范围
+func rasterLimits[T RasterElement]() (float64, float64) {
+	var v T
+	switch any(v).(type) {
+	case int8:
+		return math.MinInt8, math.MaxInt8
+	case uint8:
+		return 0, math.MaxUint8
+	case int16:
+		return math.MinInt16, math.MaxInt16
+	case uint16:
+		return 0, math.MaxUint16
+	case int32:
+		return math.MinInt32, math.MaxInt32
+	case uint32:
+		return 0, math.MaxUint32
+	case int64:
+		return math.MinInt64, math.MaxInt64
+	case uint64:
+		return 0, math.MaxUint64
+	case float32:
+		return -math.MaxFloat32, math.MaxFloat32
+	}
+	return -math.MaxFloat64, math.MaxFloat64
+}
+
+// 栅格尺寸与地理参考，与像元类型无关
+// 像元与地理坐标的对应关系完全由 geoTransform 与 pixelIsPoint 决定
+type RasterGrid struct {
+	Size     [2]int
+	Bounds   [4]float64 // 外包范围 [北, 南, 东, 西]，设置地理变换时计算，只读
+	Hemlines bool
+
+	geoTransform GeoTransform
+	inverse      GeoTransform // 像元为面时的逆变换
+	pixelIsPoint bool         // 地理变换原点为像元中心而非左上角
+	transform    func(*Vertex) Vertex
+}
+
+// 按行存储的栅格，Data 长度为 行数*列数
+// 内嵌的 Raster 为旧接口的无类型视图，与 Data 共用底层数组，由构造函数设置；
+// 直接替换 Data 或 NoData 后视图不再同步
+type RasterOf[T RasterElement] struct {
+	Raster
+	NoData T
+	Data   []T
+}
+
+type (
+	RasterDouble = RasterOf[float64]
+	RasterFloat  = RasterOf[float32]
+	RasterShort  = RasterOf[int16]
+	RasterChar   = RasterOf[int8]
+	RasterInt    = RasterOf[int32]
+)
+
+// 无类型栅格，Data 为 []T 切片，NoData 为对应类型的值
+// 每次读写都要按类型分支，仅为兼容旧接口保留，新代码应使用 RasterOf[T]
 type Raster struct {
-	Size      [2]int
-	Bounds    [4]float64
-	NoData    interface{}
-	Type      int32
-	Data      interface{}
-	Hemlines  bool
-	pos       [2]float64
-	cellsize  float64
-	transform func(*Vertex) Vertex
+	RasterGrid
+	NoData interface{}
+	Type   int32
+	Data   interface{}
 }
 
-func NewRasterWithNoData(row, column int, noData interface{}) *Raster {
-	r := Raster{}
+func newRasterOf[T RasterElement](row, column int, data []T, noData T) *RasterOf[T] {
+	r := &RasterOf[T]{NoData: noData, Data: data}
+	r.Raster = Raster{NoData: noData, Type: int32(RasterDataType[T]()), Data: data}
 	r.Size = [2]int{row, column}
+	return r
+}
+
+// 创建以 noData 填充的栅格
+func NewRasterOf[T RasterElement](row, column int, noData T) *RasterOf[T] {
+	r := newRasterOf(row, column, make([]T, row*column), noData)
+	r.Fill(noData)
+	return r
+}
+
+// 使用已有数据创建栅格，无效值取像元类型的默认无效值
+func NewRasterOfWithData[T RasterElement](row, column int, data []T) *RasterOf[T] {
+	return newRasterOf(row, column, data, defaultNoData[T]())
+}
+
+// 按 noData 的类型创建以其填充的无类型栅格
+func NewRasterWithNoData(row, column int, noData interface{}) *Raster {
 	switch t := noData.(type) {
 	case int8:
-		r.Type = RASTER_DATA_TYPE_INT8
-		r.NoData = t
-		ds := make([]int8, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	case uint8:
-		r.Type = RASTER_DATA_TYPE_UINT8
-		r.NoData = t
-		ds := make([]uint8, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	case int16:
-		r.Type = RASTER_DATA_TYPE_INT16
-		r.NoData = t
-		ds := make([]int16, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	case uint16:
-		r.Type = RASTER_DATA_TYPE_UINT16
-		r.NoData = t
-		ds := make([]uint16, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	case int32:
-		r.Type = RASTER_DATA_TYPE_INT32
-		r.NoData = t
-		ds := make([]int32, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	case uint32:
-		r.Type = RASTER_DATA_TYPE_UINT32
-		r.NoData = t
-		ds := make([]uint32, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	case int64:
-		r.Type = RASTER_DATA_TYPE_INT64
-		r.NoData = t
-		ds := make([]int64, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	case uint64:
-		r.Type = RASTER_DATA_TYPE_UINT64
-		r.NoData = t
-		ds := make([]uint64, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	case float32:
-		r.Type = RASTER_DATA_TYPE_FLOAT32
-		r.NoData = t
-		ds := make([]float32, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	case float64:
-		r.Type = RASTER_DATA_TYPE_FLOAT64
-		r.NoData = t
-		ds := make([]float64, row*column)
-		for i := 0; i < row*column; i++ {
-			ds[i] = t
-		}
-		r.Data = ds
+		return &NewRasterOf(row, column, t).Raster
 	}
-	return &r
+	r := &Raster{}
+	r.Size = [2]int{row, column}
+	return r
 }
 
+// 按 RASTER_DATA_TYPE_* 创建像元为零值的无类型栅格，NoData 为 -400.0
 func NewRaster(row, column, dataType int) *Raster {
-	r := Raster{}
-	r.Size = [2]int{row, column}
-	r.Type = int32(dataType)
-	r.NoData = -400.0
+	var r *Raster
+	n := row * column
 	switch dataType {
 	case RASTER_DATA_TYPE_INT8:
-		r.Data = make([]int8, row*column)
+		r = &NewRasterOfWithData(row, column, make([]int8, n)).Raster
 	case RASTER_DATA_TYPE_UINT8:
-		r.Data = make([]uint8, row*column)
+		r = &NewRasterOfWithData(row, column, make([]uint8, n)).Raster
 	case RASTER_DATA_TYPE_INT16:
-		r.Data = make([]int16, row*column)
+		r = &NewRasterOfWithData(row, column, make([]int16, n)).Raster
 	case RASTER_DATA_TYPE_UINT16:
-		r.Data = make([]uint16, row*column)
+		r = &NewRasterOfWithData(row, column, make([]uint16, n)).Raster
 	case RASTER_DATA_TYPE_INT32:
-		r.Data = make([]int32, row*column)
+		r = &NewRasterOfWithData(row, column, make([]int32, n)).Raster
 	case RASTER_DATA_TYPE_UINT32:
-		r.Data = make([]uint32, row*column)
+		r = &NewRasterOfWithData(row, column, make([]uint32, n)).Raster
 	case RASTER_DATA_TYPE_INT64:
-		r.Data = make([]int64, row*column)
+		r = &NewRasterOfWithData(row, column, make([]int64, n)).Raster
 	case RASTER_DATA_TYPE_UINT64:
-		r.Data = make([]uint64, row*column)
+		r = &NewRasterOfWithData(row, column, make([]uint64, n)).Raster
 	case RASTER_DATA_TYPE_FLOAT32:
-		r.Data = make([]float32, row*column)
+		r = &NewRasterOfWithData(row, column, make([]float32, n)).Raster
 	case RASTER_DATA_TYPE_FLOAT64:
-		r.Data = make([]float64, row*column)
+		r = &NewRasterOfWithData(row, column, make([]float64, n)).Raster
+	default:
+		r = &Raster{Type: int32(dataType)}
+		r.Size = [2]int{row, column}
 	}
-	return &r
+	r.NoData = -400.0
+	return r
 }
 
+// 按 data 的切片类型创建无类型栅格，无效值取像元类型的默认无效值
+// 不支持的类型创建以 NaN 为无效值的零值双精度栅格
 func NewRasterWithData(row, column int, data interface{}) *Raster {
-	r := Raster{}
-	r.Size = [2]int{row, column}
-
 	switch t := data.(type) {
 	case []int8:
-		r.Type = RASTER_DATA_TYPE_INT8
-		r.Data = t
-		r.NoData = int8(-128) // 最小可能值
+		return &NewRasterOfWithData(row, column, t).Raster
 	case []uint8:
-		r.Type = RASTER_DATA_TYPE_UINT8
-		r.Data = t
-		r.NoData = uint8(0) // 0值作为无效值
+		return &NewRasterOfWithData(row, column, t).Raster
 	case []int16:
-		r.Type = RASTER_DATA_TYPE_INT16
-		r.Data = t
-		r.NoData = int16(-32768) // 最小可能值
+		return &NewRasterOfWithData(row, column, t).Raster
 	case []uint16:
-		r.Type = RASTER_DATA_TYPE_UINT16
-		r.Data = t
-		r.NoData = uint16(0) // 0值作为无效值
+		return &NewRasterOfWithData(row, column, t).Raster
 	case []int32:
-		r.Type = RASTER_DATA_TYPE_INT32
-		r.Data = t
-		r.NoData = int32(-2147483648) // 最小可能值
+		return &NewRasterOfWithData(row, column, t).Raster
 	case []uint32:
-		r.Type = RASTER_DATA_TYPE_UINT32
-		r.Data = t
-		r.NoData = uint32(0) // 0值作为无效值
+		return &NewRasterOfWithData(row, column, t).Raster
 	case []int64:
-		r.Type = RASTER_DATA_TYPE_INT64
-		r.Data = t
-		r.NoData = int64(-9223372036854775808) // 最小可能值
+		return &NewRasterOfWithData(row, column, t).Raster
 	case []uint64:
-		r.Type = RASTER_DATA_TYPE_UINT64
-		r.Data = t
-		r.NoData = uint64(0) // 0值作为无效值
+		return &NewRasterOfWithData(row, column, t).Raster
 	case []float32:
-		r.Type = RASTER_DATA_TYPE_FLOAT32
-		r.Data = t
-		r.NoData = float32(math.NaN()) // NaN表示无效
+		return &NewRasterOfWithData(row, column, t).Raster
 	case []float64:
-		r.Type = RASTER_DATA_TYPE_FLOAT64
-		r.Data = t
-		r.NoData = math.NaN() // NaN表示无效
-	default:
-		// 处理未知类型情况
-		r.Type = RASTER_DATA_TYPE_FLOAT64
-		r.Data = make([]float64, row*column)
-		r.NoData = math.NaN()
-	}
-
-	// 自动计算边界（如果位置和大小已设置）
-	if r.cellsize > 0 {
-		r.Bounds = [4]float64{
-			r.pos[1] + float64(r.Rows())*r.cellsize, // North
-			r.pos[1],                                // South
-			r.pos[0] + float64(r.Cols())*r.cellsize, // East
-			r.pos[0],                                // West
-		}
+		return &NewRasterOfWithData(row, column, t).Raster
 	}
+	return &NewRasterOfWithData(row, column, make([]float64, row*column)).Raster
+}
+
+func NewRasterDouble(row, column int, noData float64) *RasterDouble {
+	return NewRasterOf(row, column, noData)
+}
+
+func NewRasterDoubleWithData(row, column int, data []float64) *RasterDouble {
+	return NewRasterOfWithData(row, column, data)
+}
+
+func NewRasterChar(row, column int, noData int8) *RasterChar {
+	return NewRasterOf(row, column, noData)
+}
+
+func NewRasterCharWithData(row, column int, data []int8) *RasterChar {
+	return NewRasterOfWithData(row, column, data)
+}
+
+func NewRasterInt(row, column int, noData int32) *RasterInt {
+	return NewRasterOf(row, column, noData)
+}
+
+func NewRasterIntWithData(row, column int, data []int32) *RasterInt {
+	return NewRasterOfWithData(row, column, data)
+}
+
+func (r *RasterGrid) GeoTransform() GeoTransform {
+	return r.geoTransform
+}
+
+// 设置仿射地理变换，需在 Size 确定后调用
+func (r *RasterGrid) SetGeoTransform(gt GeoTransform) {
+	r.geoTransform = gt
+	r.update()
+}
+
+func (r *RasterGrid) PixelIsPoint() bool {
+	return r.pixelIsPoint
+}
+
+// 指定地理变换原点为像元中心 (true) 或左上角 (false)，不修改地理变换本身
+func (r *RasterGrid) SetPixelIsPoint(point bool) {
+	r.pixelIsPoint = point
+	r.update()
+}
 
-	return &r
+// 以左下角 (x, y) 与正方形像元 res 设置北向上、像元为面的地理变换
+func (r *RasterGrid) SetXYPos(x, y, res float64) {
+	r.SetXYPosCellSize(x, y, res, res)
 }
 
-func (r *Raster) CellSize() float64 {
-	return r.cellsize
+// 同 SetXYPos，X/Y 方向像元大小分别为 resX、resY
+func (r *RasterGrid) SetXYPosCellSize(x, y, resX, resY float64) {
+	r.pixelIsPoint = false
+	r.SetGeoTransform(NewGeoTransform(x, y+float64(r.Rows())*resY, resX, -resY))
 }
 
-func (r *Raster) SetXYPos(x, y, res float64) {
-	r.setCellSize(res)
-	r.setPosX(x)
-	r.setPosY(y)
-	r.Bounds = [4]float64{
-		y + float64(r.Rows())*res, // North
-		y,                         // South
-		x + float64(r.Cols())*res, // East
-		x,                         // West
+// 原点位于左上角像元角点的地理变换
+func (r *RasterGrid) areaTransform() GeoTransform {
+	if r.pixelIsPoint {
+		return r.geoTransform.window(-0.5, -0.5, 1)
 	}
+	return r.geoTransform
 }
 
-func (r *Raster) SetTransform(trans func(*Vertex) Vertex) { r.transform = trans }
+// 重新计算逆变换与 Bounds
+func (r *RasterGrid) update() {
+	area := r.areaTransform()
+	r.inverse, _ = area.Invert()
+	e := area.extent(r.Cols(), r.Rows())
+	r.Bounds = [4]float64{e.Max[1], e.Min[1], e.Max[0], e.Min[0]}
+}
 
-func (r *Raster) setPosX(xpos float64) { r.pos[0] = xpos }
+// 栅格单元覆盖范围的外包矩形
+func (r *RasterGrid) Extent() vec2d.Rect {
+	return r.areaTransform().extent(r.Cols(), r.Rows())
+}
 
-func (r *Raster) setPosY(ypos float64) { r.pos[1] = ypos }
+// X 方向像元大小
+func (r *RasterGrid) CellSizeX() float64 {
+	return math.Hypot(r.geoTransform[1], r.geoTransform[4])
+}
 
-func (r *Raster) setCellSize(cs float64) { r.cellsize = cs }
+// Y 方向像元大小
+func (r *RasterGrid) CellSizeY() float64 {
+	return math.Hypot(r.geoTransform[2], r.geoTransform[5])
+}
 
-func (r *Raster) Rows() int {
+// X 方向像元大小，正方形像元时即像元边长
+func (r *RasterGrid) CellSize() float64 {
+	return r.CellSizeX()
+}
+
+// 较小的像元边长，用于确定采样步长
+func (r *RasterGrid) minCellSize() float64 {
+	return math.Min(r.CellSizeX(), r.CellSizeY())
+}
+
+// 较大的像元边长，用于与层级分辨率比较
+func (r *RasterGrid) maxCellSize() float64 {
+	return math.Max(r.CellSizeX(), r.CellSizeY())
+}
+
+func (r *RasterGrid) SetTransform(trans func(*Vertex) Vertex) { r.transform = trans }
+
+func (r *RasterGrid) Rows() int {
 	return r.Size[0]
 }
 
-func (r *Raster) Cols() int {
+func (r *RasterGrid) Cols() int {
 	return r.Size[1]
 }
 
-func (r *Raster) Count() int {
+func (r *RasterGrid) Count() int {
 	return r.Size[0] * r.Size[1]
 }
 
-func (r *Raster) North() float64 {
+func (r *RasterGrid) North() float64 {
 	return r.Bounds[0]
 }
 
-func (r *Raster) South() float64 {
+func (r *RasterGrid) South() float64 {
 	return r.Bounds[1]
 }
 
-func (r *Raster) East() float64 {
+func (r *RasterGrid) East() float64 {
 	return r.Bounds[2]
 }
 
-func (r *Raster) West() float64 {
+func (r *RasterGrid) West() float64 {
 	return r.Bounds[3]
 }
 
-func (r *Raster) Value(row, column int) interface{} {
-	switch t := r.Data.(type) {
-	case []int8:
-		return t[row*r.Cols()+column]
-	case []uint8:
-		return t[row*r.Cols()+column]
-	case []int16:
-		return t[row*r.Cols()+column]
-	case []uint16:
-		return t[row*r.Cols()+column]
-	case []int32:
-		return t[row*r.Cols()+column]
-	case []uint32:
-		return t[row*r.Cols()+column]
-	case []int64:
-		return t[row*r.Cols()+column]
-	case []uint64:
-		return t[row*r.Cols()+column]
-	case []float32:
-		return t[row*r.Cols()+column]
-	case []float64:
-		return t[row*r.Cols()+column]
+// 半球网格的首末行列取相邻行列
+func (r *RasterGrid) hemline(i, n int) int {
+	if r.Hemlines {
+		switch i {
+		case n - 1:
+			i--
+		case 0:
+			i++
+		}
 	}
-	return r.NoData
+	return i
 }
 
-func (r *Raster) SetValue(row, column int, data interface{}) {
-	switch t := r.Data.(type) {
-	case []int8:
-		t[row*r.Cols()+column] = data.(int8)
-	case []uint8:
-		t[row*r.Cols()+column] = data.(uint8)
-	case []int16:
-		t[row*r.Cols()+column] = data.(int16)
-	case []uint16:
-		t[row*r.Cols()+column] = data.(uint16)
-	case []int32:
-		t[row*r.Cols()+column] = data.(int32)
-	case []uint32:
-		t[row*r.Cols()+column] = data.(uint32)
-	case []int64:
-		t[row*r.Cols()+column] = data.(int64)
-	case []uint64:
-		t[row*r.Cols()+column] = data.(uint64)
-	case []float32:
-		t[row*r.Cols()+column] = data.(float32)
-	case []float64:
-		t[row*r.Cols()+column] = data.(float64)
-	}
-}
-
-func (r *Raster) GetRow(row int) interface{} {
-	switch t := r.Data.(type) {
-	case []int8:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	case []uint8:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	case []int16:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	case []uint16:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	case []int32:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	case []uint32:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	case []int64:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	case []uint64:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	case []float32:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	case []float64:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	}
-	return nil
+// 像元 (row, col) 中心的地理坐标
+func (r *RasterGrid) CellCenter(row, col int) (float64, float64) {
+	return r.pixelToGeo(float64(r.hemline(col, r.Size[1])), float64(r.hemline(row, r.Size[0])))
 }
 
 // ColToX converts column index to X coordinate (center of cell)
-func (r *Raster) ColToX(col int) float64 {
-	// Handle edge cases for hemispheric grids
-	if r.Hemlines {
-		switch col {
-		case r.Size[1] - 1:
-			col--
-		case 0:
-			col++
-		}
-	}
-	return r.pos[0] + (float64(col)+0.5)*r.cellsize
+// 有旋转项时取第 0 行，应改用 CellCenter
+func (r *RasterGrid) ColToX(col int) float64 {
+	x, _ := r.pixelToGeo(float64(r.hemline(col, r.Size[1])), 0)
+	return x
 }
 
 // XToCol converts X coordinate to column index
-func (r *Raster) XToCol(x float64) int {
-	if r.cellsize <= 0 {
+// 只适用于无旋转项的栅格，有旋转项时列号同时取决于 Y，返回 -1，应改用 GeoTransform().Invert()
+func (r *RasterGrid) XToCol(x float64) int {
+	if !r.geoTransform.IsAxisAligned() {
+		return -1
+	}
+	if r.CellSizeX() <= 0 {
 		return 0
 	}
-	col := int(math.Floor((x - r.pos[0]) / r.cellsize))
+	col := int(math.Floor(r.inverse[0] + x*r.inverse[1]))
 
 	// Boundary checks
 	if col < 0 {
@@ -373,32 +452,22 @@ func (r *Raster) XToCol(x float64) int {
 }
 
 // RowToY converts row index to Y coordinate (center of cell)
-func (r *Raster) RowToY(row int) float64 {
-	// Handle edge cases for hemispheric grids
-	if r.Hemlines {
-		switch row {
-		case r.Size[0] - 1:
-			row--
-		case 0:
-			row++
-		}
-	}
-	// Convert from top-left origin to bottom-left origin
-	rowFromBottom := r.Size[0] - 1 - row
-	return r.pos[1] + (float64(rowFromBottom)+0.5)*r.cellsize
+// 有旋转项时取第 0 列，应改用 CellCenter
+func (r *RasterGrid) RowToY(row int) float64 {
+	_, y := r.pixelToGeo(0, float64(r.hemline(row, r.Size[0])))
+	return y
 }
 
 // YToRow converts Y coordinate to row index
-func (r *Raster) YToRow(y float64) int {
-	if r.cellsize <= 0 {
+// 只适用于无旋转项的栅格，有旋转项时返回 -1，见 XToCol
+func (r *RasterGrid) YToRow(y float64) int {
+	if !r.geoTransform.IsAxisAligned() {
+		return -1
+	}
+	if r.CellSizeY() <= 0 {
 		return 0
 	}
-
-	// Calculate row from bottom
-	rowFromBottom := int(math.Floor((y - r.pos[1]) / r.cellsize))
-
-	// Convert to top-left origin
-	row := r.Size[0] - 1 - rowFromBottom
+	row := int(math.Floor(r.inverse[3] + y*r.inverse[5]))
 
 	// Boundary checks
 	if row < 0 {
@@ -411,235 +480,318 @@ func (r *Raster) YToRow(y float64) int {
 }
 
 // RowBottomToY converts row index from bottom to Y coordinate
-func (r *Raster) RowBottomToY(rowFromBottom int) float64 {
-	return r.pos[1] + (float64(rowFromBottom)+0.5)*r.cellsize
+func (r *RasterGrid) RowBottomToY(rowFromBottom int) float64 {
+	_, y := r.pixelToGeo(0, float64(r.Size[0]-1-rowFromBottom))
+	return y
 }
 
 // ColLeftToX converts column index to X coordinate
-func (r *Raster) ColLeftToX(col int) float64 {
+func (r *RasterGrid) ColLeftToX(col int) float64 {
 	return r.ColToX(col)
 }
 
-type VertexReceiverFn func(x, y float64, v interface{})
+// 将地理坐标转换为连续的像素坐标 (列, 行)，单元中心为整数
+func (r *RasterGrid) geoToPixel(x, y float64) (float64, float64) {
+	col, row := r.inverse.Apply(x, y)
+	return col - 0.5, row - 0.5
+}
 
-func (r *Raster) ToVertices(receiverFn VertexReceiverFn) {
-	cs := r.cellsize
-	xpos := r.pos[0]
-	ypos := r.pos[1]
-	width := r.Cols()
-	height := r.Rows()
-
-	for row := 0; row < height; row++ {
-		yCoordinate := ypos + (float64(height)-float64(row)-1)*cs
-		for c := 0; c < width; c++ {
-			xCoordinate := xpos + float64(c)*cs
-			receiverFn(xCoordinate, yCoordinate, r.Value(row, c))
-		}
-	}
+// geoToPixel 的逆变换
+func (r *RasterGrid) pixelToGeo(col, row float64) (float64, float64) {
+	return r.areaTransform().Apply(col+0.5, row+0.5)
 }
 
-type RasterDouble struct {
-	Raster
+// 复制地理参考信息
+func (r *RasterGrid) copyGeoReference(o *RasterGrid) {
+	r.Hemlines = o.Hemlines
+	r.geoTransform = o.geoTransform
+	r.pixelIsPoint = o.pixelIsPoint
+	r.transform = o.transform
+	r.update()
 }
 
-func NewRasterDouble(row, column int, noData float64) *RasterDouble {
-	r := RasterDouble{}
-	r.Size = [2]int{row, column}
-	r.Type = int32(RASTER_DATA_TYPE_FLOAT64)
-	r.NoData = noData
-	dt := make([]float64, row*column)
-	for i := range dt {
-		dt[i] = noData
+// 地理参考设为 o 中从 (row, col) 开始、像元放大 factor 倍的窗口，需在 Size 确定后调用
+// 像元为点的栅格降采样后按像元为面处理
+func (r *RasterGrid) setWindowGeoReference(o *RasterGrid, row, col, factor int) {
+	gt, point := o.geoTransform, o.pixelIsPoint
+	if factor > 1 && point {
+		gt, point = o.areaTransform(), false
 	}
-	r.Data = dt
-	return &r
+	r.Hemlines = o.Hemlines
+	r.transform = o.transform
+	r.pixelIsPoint = point
+	r.SetGeoTransform(gt.window(float64(col), float64(row), float64(max(factor, 1))))
 }
 
-func NewRasterDoubleWithData(row, column int, data []float64) *RasterDouble {
-	r := RasterDouble{}
-	r.Size = [2]int{row, column}
-	r.Type = int32(RASTER_DATA_TYPE_FLOAT64)
-	r.Data = data
-	r.NoData = math.NaN()
-	return &r
+// RASTER_DATA_TYPE_* 编码
+func (r *RasterOf[T]) Type() int {
+	return RasterDataType[T]()
 }
 
-func (r *RasterDouble) DataSlice() []float64 {
-	if data, ok := r.Data.([]float64); ok {
-		return data
-	}
-	return nil
+func (r *RasterOf[T]) DataSlice() []T {
+	return r.Data
 }
 
-func (r *RasterDouble) Fill(data float64) {
-	if t, ok := r.Data.([]float64); ok {
-		for i := range t {
-			t[i] = data
-		}
+func (r *RasterOf[T]) Fill(data T) {
+	for i := range r.Data {
+		r.Data[i] = data
 	}
 }
 
-func (r *RasterDouble) Value(row, column int) float64 {
-	switch t := r.Data.(type) {
-	case []float64:
-		return t[row*r.Cols()+column]
-	}
-	return r.NoData.(float64)
+// 深拷贝数据及地理参考信息
+func (r *RasterOf[T]) Clone() *RasterOf[T] {
+	c := newRasterOf(r.Rows(), r.Cols(), append([]T(nil), r.Data...), r.NoData)
+	c.copyGeoReference(&r.RasterGrid)
+	return c
 }
 
-func (r *RasterDouble) SetValue(row, column int, data float64) {
-	switch t := r.Data.(type) {
-	case []float64:
-		t[row*r.Cols()+column] = data
-	}
+func (r *RasterOf[T]) Value(row, column int) T {
+	return r.Data[row*r.Cols()+column]
 }
 
-func (r *RasterDouble) GetRow(row int) []float64 {
-	switch t := r.Data.(type) {
-	case []float64:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	}
-	return nil
+func (r *RasterOf[T]) SetValue(row, column int, data T) {
+	r.Data[row*r.Cols()+column] = data
 }
 
-type RasterChar struct {
-	Raster
+func (r *RasterOf[T]) GetRow(row int) []T {
+	return r.Data[row*r.Cols() : (row+1)*r.Cols()]
 }
 
-func NewRasterChar(row, column int, noData int8) *RasterChar {
-	r := RasterChar{}
-	r.Size = [2]int{row, column}
-	r.Type = int32(RASTER_DATA_TYPE_INT8)
-	r.NoData = noData
-	dt := make([]int8, row*column)
-	for i := range dt {
-		dt[i] = noData
-	}
-	r.Data = dt
-	return &r
+// v 是否为无效值，NoData 为 NaN 时任意 NaN 均视为无效
+func (r *RasterOf[T]) IsNoData(v T) bool {
+	return v == r.NoData || (v != v && r.NoData != r.NoData)
 }
 
-func NewRasterCharWithData(row, column int, data []int8) *RasterChar {
-	r := RasterChar{}
-	r.Size = [2]int{row, column}
-	r.Type = int32(RASTER_DATA_TYPE_INT8)
-	r.Data = data
-	r.NoData = math.NaN()
-
-	return &r
+func (r *RasterOf[T]) Grid() *RasterGrid {
+	return &r.RasterGrid
 }
 
-func (r *RasterChar) DataSlice() []int8 {
-	if data, ok := r.Data.([]int8); ok {
-		return data
+// 以 float64 读取像元值，无效值及超出 Data 的位置返回 NaN
+func (r *RasterOf[T]) Elevation(row, column int) float64 {
+	i := row*r.Cols() + column
+	if uint(i) >= uint(len(r.Data)) {
+		return math.NaN()
 	}
-	return nil
+	v := r.Data[i]
+	if r.IsNoData(v) {
+		return math.NaN()
+	}
+	return float64(v)
 }
 
-func (r *RasterChar) Fill(data int8) {
-	if t, ok := r.Data.([]int8); ok {
-		for i := range t {
-			t[i] = data
-		}
+// 以 float64 写入像元值，NaN 写为无效值
+func (r *RasterOf[T]) SetElevation(row, column int, z float64) {
+	v, ok := toElement[T](z)
+	if !ok {
+		v = r.NoData
 	}
+	r.SetValue(row, column, v)
 }
 
-func (r *RasterChar) Value(row, column int) int8 {
-	switch t := r.Data.(type) {
-	case []int8:
-		return t[row*r.Cols()+column]
-	}
-	return r.NoData.(int8)
+// 与像元类型无关的高程读写接口，TIN 生成可直接使用 float32/int16 等窄类型 DEM
+// 无效值读取为 NaN
+type ElevationRaster interface {
+	Grid() *RasterGrid
+	Elevation(row, column int) float64
+	SetElevation(row, column int, z float64)
 }
 
-func (r *RasterChar) SetValue(row, column int, data int8) {
-	switch t := r.Data.(type) {
-	case []int8:
-		t[row*r.Cols()+column] = data
-	}
+// 带比例与偏移的窄类型 DEM，高程 = 像元值*Scale + Offset
+type ScaledRaster[T RasterElement] struct {
+	*RasterOf[T]
+	Scale  float64
+	Offset float64
 }
 
-func (r *RasterChar) GetRow(row int) []int8 {
-	switch t := r.Data.(type) {
-	case []int8:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
-	}
-	return nil
+func NewScaledRaster[T RasterElement](raster *RasterOf[T], scale, offset float64) *ScaledRaster[T] {
+	return &ScaledRaster[T]{RasterOf: raster, Scale: scale, Offset: offset}
 }
 
-type RasterInt struct {
-	Raster
+func (s *ScaledRaster[T]) Elevation(row, column int) float64 {
+	return s.RasterOf.Elevation(row, column)*s.Scale + s.Offset
 }
 
-func NewRasterInt(row, column int, noData int32) *RasterInt {
-	r := RasterInt{}
-	r.Size = [2]int{row, column}
-	r.Type = int32(RASTER_DATA_TYPE_INT32)
-	r.NoData = noData
-	dt := make([]int32, row*column)
-	for i := range dt {
-		dt[i] = noData
+func (s *ScaledRaster[T]) SetElevation(row, column int, z float64) {
+	s.RasterOf.SetElevation(row, column, (z-s.Offset)/s.Scale)
+}
+
+// 复制为以 NaN 为无效值的双精度栅格
+func elevationToDouble(src ElevationRaster) *RasterDouble {
+	if r, ok := src.(*RasterDouble); ok {
+		return r
 	}
-	r.Data = dt
-	return &r
+	g := src.Grid()
+	dst := NewRasterDouble(g.Rows(), g.Cols(), math.NaN())
+	dst.copyGeoReference(g)
+	for row := 0; row < g.Rows(); row++ {
+		for col := 0; col < g.Cols(); col++ {
+			dst.SetValue(row, col, src.Elevation(row, col))
+		}
+	}
+	return dst
 }
 
-func NewRasterIntWithData(row, column int, data []int32) *RasterInt {
-	r := RasterInt{}
-	r.Size = [2]int{row, column}
-	r.Type = int32(RASTER_DATA_TYPE_INT32)
-	r.Data = data
-	r.NoData = math.NaN()
+type VertexReceiverOf[T RasterElement] func(x, y float64, v T)
 
-	return &r
+// 按行遍历像元，坐标为北向上栅格中像元的左下角
+func (r *RasterOf[T]) ToVertices(receiverFn VertexReceiverOf[T]) {
+	gt := r.areaTransform()
+	for row := 0; row < r.Rows(); row++ {
+		for c := 0; c < r.Cols(); c++ {
+			x, y := gt.Apply(float64(c), float64(row+1))
+			receiverFn(x, y, r.Value(row, c))
+		}
+	}
 }
 
-func (r *RasterInt) DataSlice() []int32 {
-	if data, ok := r.Data.([]int32); ok {
-		return data
+// 转换像元类型：dst = src*scale + offset，整数类型四舍五入并截断到取值范围
+// 源栅格的无效值及换算结果为 NaN 的像元转换为 noData
+func ConvertRaster[D, S RasterElement](src *RasterOf[S], scale, offset float64, noData D) *RasterOf[D] {
+	dst := newRasterOf(src.Rows(), src.Cols(), make([]D, len(src.Data)), noData)
+	dst.copyGeoReference(&src.RasterGrid)
+
+	for i, v := range src.Data {
+		if src.IsNoData(v) {
+			dst.Data[i] = noData
+			continue
+		}
+		d, ok := toElement[D](float64(v)*scale + offset)
+		if !ok {
+			d = noData
+		}
+		dst.Data[i] = d
 	}
-	return nil
+	return dst
 }
 
-func (r *RasterInt) Fill(data int32) {
-	if t, ok := r.Data.([]int32); ok {
-		for i := range t {
-			t[i] = data
-		}
+// 将浮点数转换为像元类型，整数类型先四舍五入
+// 超出取值范围 (含 ±Inf) 的值截断到端点，NaN 不转换并返回 false
+func toElement[T RasterElement](f float64) (T, bool) {
+	if math.IsNaN(f) {
+		return 0, false
+	}
+	lo, hi := rasterLimits[T]()
+	if f <= lo {
+		return T(lo), true
+	}
+	// int64/uint64 的上界在 float64 中向上舍入到 2^63/2^64，不能直接转换
+	if f >= hi {
+		return maxElement[T](), true
+	}
+	if RasterDataType[T]() < RASTER_DATA_TYPE_FLOAT32 {
+		f = math.Round(f)
+	}
+	return T(f), true
+}
+
+// 转换为以 NaN 为无效值的双精度栅格，常用于 int16/float32 DEM
+func ToRasterDouble[S RasterElement](src *RasterOf[S], scale, offset float64) *RasterDouble {
+	return ConvertRaster(src, scale, offset, math.NaN())
+}
+
+// 检查是否为 NaN 的辅助函数
+func IsNaN(v interface{}) bool {
+	switch v := v.(type) {
+	case float32:
+		return math.IsNaN(float64(v))
+	case float64:
+		return math.IsNaN(v)
+	default:
+		return false
 	}
 }
 
-func (r *RasterInt) Value(row, column int) int32 {
+func (r *Raster) Value(row, column int) interface{} {
+	i := row*r.Cols() + column
 	switch t := r.Data.(type) {
+	case []int8:
+		return t[i]
+	case []uint8:
+		return t[i]
+	case []int16:
+		return t[i]
+	case []uint16:
+		return t[i]
 	case []int32:
-		return t[row*r.Cols()+column]
+		return t[i]
+	case []uint32:
+		return t[i]
+	case []int64:
+		return t[i]
+	case []uint64:
+		return t[i]
+	case []float32:
+		return t[i]
+	case []float64:
+		return t[i]
 	}
-	return r.NoData.(int32)
+	return r.NoData
 }
 
-func (r *RasterInt) SetValue(row, column int, data int32) {
+// data 的类型须与像元类型一致
+func (r *Raster) SetValue(row, column int, data interface{}) {
+	i := row*r.Cols() + column
 	switch t := r.Data.(type) {
+	case []int8:
+		t[i] = data.(int8)
+	case []uint8:
+		t[i] = data.(uint8)
+	case []int16:
+		t[i] = data.(int16)
+	case []uint16:
+		t[i] = data.(uint16)
 	case []int32:
-		t[row*r.Cols()+column] = data
+		t[i] = data.(int32)
+	case []uint32:
+		t[i] = data.(uint32)
+	case []int64:
+		t[i] = data.(int64)
+	case []uint64:
+		t[i] = data.(uint64)
+	case []float32:
+		t[i] = data.(float32)
+	case []float64:
+		t[i] = data.(float64)
 	}
 }
 
-func (r *RasterInt) GetRow(row int) []int32 {
+// 返回对应类型的行切片
+func (r *Raster) GetRow(row int) interface{} {
+	lo, hi := row*r.Cols(), (row+1)*r.Cols()
 	switch t := r.Data.(type) {
+	case []int8:
+		return t[lo:hi]
+	case []uint8:
+		return t[lo:hi]
+	case []int16:
+		return t[lo:hi]
+	case []uint16:
+		return t[lo:hi]
 	case []int32:
-		return t[row*r.Cols() : (row+1)*r.Cols()]
+		return t[lo:hi]
+	case []uint32:
+		return t[lo:hi]
+	case []int64:
+		return t[lo:hi]
+	case []uint64:
+		return t[lo:hi]
+	case []float32:
+		return t[lo:hi]
+	case []float64:
+		return t[lo:hi]
 	}
 	return nil
 }
 
-// 检查是否为 NaN 的辅助函数
-func IsNaN(v interface{}) bool {
-	switch v := v.(type) {
-	case float32:
-		return math.IsNaN(float64(v))
-	case float64:
-		return math.IsNaN(v)
-	default:
-		return false
+type VertexReceiverFn func(x, y float64, v interface{})
+
+// 同 RasterOf.ToVertices
+func (r *Raster) ToVertices(receiverFn VertexReceiverFn) {
+	gt := r.areaTransform()
+	for row := 0; row < r.Rows(); row++ {
+		for c := 0; c < r.Cols(); c++ {
+			x, y := gt.Apply(float64(c), float64(row+1))
+			receiverFn(x, y, r.Value(row, c))
+		}
 	}
 }
@@ -0,0 +1,88 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+)
+
+// 检查两个栅格是否对齐：尺寸与地理变换一致
+func checkAligned(a, b *RasterDouble) error {
+	if a == nil || b == nil {
+		return fmt.Errorf("nil raster")
+	}
+	if a.Size != b.Size {
+		return fmt.Errorf("raster size mismatch: %v vs %v", a.Size, b.Size)
+	}
+	tol := EPS * math.Max(1, a.CellSize())
+	ga, gb := a.areaTransform(), b.areaTransform()
+	for i := range ga {
+		if math.Abs(ga[i]-gb[i]) > tol {
+			return fmt.Errorf("rasters are not aligned")
+		}
+	}
+	return nil
+}
+
+// 逐单元计算 fn(a, b)，任一输入为 NoData 或结果为 NaN 时输出 NoData
+// 结果沿用 a 的 NoData 与地理参考
+func RasterCombine(a, b *RasterDouble, fn func(x, y float64) float64) (*RasterDouble, error) {
+	if err := checkAligned(a, b); err != nil {
+		return nil, err
+	}
+	dst := NewRasterDouble(a.Rows(), a.Cols(), a.NoData)
+	dst.copyGeoReference(&a.RasterGrid)
+	for i, x := range a.Data {
+		y := b.Data[i]
+		if isNoData(x, a.NoData) || isNoData(y, b.NoData) {
+			continue
+		}
+		if v := fn(x, y); !math.IsNaN(v) {
+			dst.Data[i] = v
+		}
+	}
+	return dst, nil
+}
+
+// 逐单元计算 fn(v)，NoData 单元保持不变
+func RasterApply(src *RasterDouble, fn func(v float64) float64) *RasterDouble {
+	dst := NewRasterDouble(src.Rows(), src.Cols(), src.NoData)
+	dst.copyGeoReference(&src.RasterGrid)
+	for i, v := range src.Data {
+		if isNoData(v, src.NoData) {
+			continue
+		}
+		if r := fn(v); !math.IsNaN(r) {
+			dst.Data[i] = r
+		}
+	}
+	return dst
+}
+
+func RasterAdd(a, b *RasterDouble) (*RasterDouble, error) {
+	return RasterCombine(a, b, func(x, y float64) float64 { return x + y })
+}
+
+// a - b，如 DSM 减 DEM 得到地物高度
+func RasterSubtract(a, b *RasterDouble) (*RasterDouble, error) {
+	return RasterCombine(a, b, func(x, y float64) float64 { return x - y })
+}
+
+// v*scale + offset
+func RasterScale(src *RasterDouble, scale, offset float64) *RasterDouble {
+	return RasterApply(src, func(v float64) float64 { return v*scale + offset })
+}
+
+// 将有效值限制在 [lo, hi]
+func RasterClamp(src *RasterDouble, lo, hi float64) *RasterDouble {
+	return RasterApply(src, func(v float64) float64 { return math.Max(lo, math.Min(hi, v)) })
+}
+
+// 掩膜为 NoData 或 0 的单元置为 NoData，其余保留 src 的值
+func RasterMask(src, mask *RasterDouble) (*RasterDouble, error) {
+	return RasterCombine(src, mask, func(x, m float64) float64 {
+		if m == 0 {
+			return math.NaN()
+		}
+		return x
+	})
+}
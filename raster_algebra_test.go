@@ -0,0 +1,50 @@
+package tin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRasterAlgebra(t *testing.T) {
+	a := NewRasterDoubleWithData(2, 2, []float64{1, 2, math.NaN(), 4})
+	b := NewRasterDoubleWithData(2, 2, []float64{10, -9999, 30, 40})
+	b.NoData = -9999
+	a.SetXYPos(100, 200, 5)
+	b.SetXYPos(100, 200, 5)
+
+	sum, err := RasterAdd(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, 11.0, sum.Value(0, 0))
+	assert.True(t, math.IsNaN(sum.Value(0, 1)))
+	assert.True(t, math.IsNaN(sum.Value(1, 0)))
+	assert.Equal(t, 44.0, sum.Value(1, 1))
+	assert.Equal(t, a.Bounds, sum.Bounds)
+
+	diff, err := RasterSubtract(b, a)
+	assert.NoError(t, err)
+	assert.Equal(t, 36.0, diff.Value(1, 1))
+	assert.Equal(t, -9999.0, diff.Value(0, 1)) // 结果沿用第一个栅格的 NoData
+
+	scaled := RasterScale(a, 2, 1)
+	assert.Equal(t, []float64{3, 5}, scaled.Data[:2])
+	assert.True(t, math.IsNaN(scaled.Value(1, 0)))
+
+	clamped := RasterClamp(b, 15, 35)
+	assert.Equal(t, []float64{15, -9999, 30, 35}, clamped.Data)
+
+	mask := NewRasterDoubleWithData(2, 2, []float64{1, 1, 0, math.NaN()})
+	mask.SetXYPos(100, 200, 5)
+	masked, err := RasterMask(b, mask)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, -9999, -9999, -9999}, masked.Data)
+
+	// 未对齐的栅格
+	c := NewRasterDouble(2, 2, math.NaN())
+	c.SetXYPos(105, 200, 5)
+	_, err = RasterAdd(a, c)
+	assert.Error(t, err)
+	_, err = RasterAdd(a, NewRasterDouble(3, 2, math.NaN()))
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+
+	vec2d "github.com/flywave/go3d/float64/vec2"
+)
+
+// 吸附到像元网格时的容差，以像元为单位
+const gridSnapTolerance = 1e-6
+
+// 将 bbox 向外吸附到像元网格，返回与栅格相交的行列范围 [row0, row1) x [col0, col1)
+// 与像元边界的偏差在 gridSnapTolerance 以内时视为对齐，不额外扩展
+func (r *RasterGrid) pixelWindow(bbox vec2d.Rect) (row0, col0, row1, col1 int, err error) {
+	if r.CellSizeX() <= 0 || r.CellSizeY() <= 0 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid cellsize: %.6f", r.CellSize())
+	}
+	// bbox 四个角点在像素空间 (像元角点为整数) 的外包范围
+	minC, minR := math.MaxFloat64, math.MaxFloat64
+	maxC, maxR := -math.MaxFloat64, -math.MaxFloat64
+	for _, p := range [4][2]float64{{bbox.Min[0], bbox.Min[1]}, {bbox.Max[0], bbox.Min[1]}, {bbox.Min[0], bbox.Max[1]}, {bbox.Max[0], bbox.Max[1]}} {
+		c, r := r.inverse.Apply(p[0], p[1])
+		minC, maxC = math.Min(minC, c), math.Max(maxC, c)
+		minR, maxR = math.Min(minR, r), math.Max(maxR, r)
+	}
+	col0 = max(0, int(math.Floor(minC+gridSnapTolerance)))
+	col1 = MinInt(r.Cols(), int(math.Ceil(maxC-gridSnapTolerance)))
+	row0 = max(0, int(math.Floor(minR+gridSnapTolerance)))
+	row1 = MinInt(r.Rows(), int(math.Ceil(maxR-gridSnapTolerance)))
+	if col0 >= col1 || row0 >= row1 {
+		return 0, 0, 0, 0, fmt.Errorf("no intersection with raster")
+	}
+	return row0, col0, row1, col1, nil
+}
+
+// 复制从 (row, col) 开始 rows 行 cols 列的子栅格，地理参考与源栅格网格对齐
+func (r *RasterOf[T]) window(row, col, rows, cols int) *RasterOf[T] {
+	dst := newRasterOf(rows, cols, make([]T, rows*cols), r.NoData)
+	dst.setWindowGeoReference(&r.RasterGrid, row, col, 1)
+	for y := 0; y < rows; y++ {
+		copy(dst.Data[y*cols:(y+1)*cols], r.Data[(row+y)*r.Cols()+col:])
+	}
+	return dst
+}
+
+// 裁剪出覆盖 bbox 与栅格交集的最小子栅格，范围向外吸附到源像元网格
+func (r *RasterOf[T]) Crop(bbox vec2d.Rect) (*RasterOf[T], error) {
+	row0, col0, row1, col1, err := r.pixelWindow(bbox)
+	if err != nil {
+		return nil, err
+	}
+	return r.window(row0, col0, row1-row0, col1-col0), nil
+}
+
+// 四周各扩展 n 个像元，新增像元取 fill
+func (r *RasterOf[T]) Pad(n int, fill T) (*RasterOf[T], error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid pad size: %d", n)
+	}
+	rows, cols := r.Rows()+2*n, r.Cols()+2*n
+	dst := NewRasterOf(rows, cols, r.NoData)
+	dst.Fill(fill)
+	dst.setWindowGeoReference(&r.RasterGrid, -n, -n, 1)
+	for y := 0; y < r.Rows(); y++ {
+		copy(dst.Data[(y+n)*cols+n:], r.Data[y*r.Cols():(y+1)*r.Cols()])
+	}
+	return dst, nil
+}
+
+// 拼接地理变换线性部分相同且网格对齐的栅格，结果覆盖所有输入的外包范围
+// 重叠处后面栅格的有效值覆盖前面的，结果沿用第一个栅格的 NoData
+func Mosaic[T RasterElement](rasters ...*RasterOf[T]) (*RasterOf[T], error) {
+	if len(rasters) == 0 || rasters[0] == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	first := rasters[0]
+	cs := first.CellSize()
+	if cs <= 0 || first.CellSizeY() <= 0 {
+		return nil, fmt.Errorf("invalid cellsize: %.6f", cs)
+	}
+	gt := first.areaTransform()
+
+	// 各栅格左上角在第一个栅格像素空间中的行列号
+	offsets := make([][2]int, len(rasters))
+	minRow, minCol, maxRow, maxCol := 0, 0, first.Rows(), first.Cols()
+	for i, r := range rasters[1:] {
+		if r == nil {
+			return nil, fmt.Errorf("nil raster")
+		}
+		g := r.areaTransform()
+		for _, k := range []int{1, 2, 4, 5} {
+			if math.Abs(g[k]-gt[k]) > gridSnapTolerance*cs {
+				return nil, fmt.Errorf("raster %d pixel size or rotation differs", i+1)
+			}
+		}
+		c, row := first.inverse.Apply(g[0], g[3])
+		if math.Abs(c-math.Round(c)) > gridSnapTolerance || math.Abs(row-math.Round(row)) > gridSnapTolerance {
+			return nil, fmt.Errorf("raster %d is not aligned to the pixel grid", i+1)
+		}
+		off := [2]int{int(math.Round(row)), int(math.Round(c))}
+		offsets[i+1] = off
+		minRow, minCol = MinInt(minRow, off[0]), MinInt(minCol, off[1])
+		maxRow, maxCol = max(maxRow, off[0]+r.Rows()), max(maxCol, off[1]+r.Cols())
+	}
+
+	rows, cols := maxRow-minRow, maxCol-minCol
+	dst := NewRasterOf(rows, cols, first.NoData)
+	dst.setWindowGeoReference(&first.RasterGrid, minRow, minCol, 1)
+	dst.Hemlines = false
+
+	for i, r := range rasters {
+		row0, col0 := offsets[i][0]-minRow, offsets[i][1]-minCol
+		for y := 0; y < r.Rows(); y++ {
+			for x := 0; x < r.Cols(); x++ {
+				if v := r.Value(y, x); !r.IsNoData(v) {
+					dst.SetValue(row0+y, col0+x, v)
+				}
+			}
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,138 @@
+package tin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flywave/go-geo"
+	vec2d "github.com/flywave/go3d/float64/vec2"
+	"github.com/stretchr/testify/assert"
+)
+
+// 10x10，像元 10，左下角 (1000, 2000)，值为 行*10+列
+func createGridRaster() *RasterDouble {
+	r := NewRasterDouble(10, 10, -9999)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			r.SetValue(y, x, float64(y*10+x))
+		}
+	}
+	r.SetXYPos(1000, 2000, 10)
+	return r
+}
+
+func TestRasterCrop(t *testing.T) {
+	src := createGridRaster()
+
+	// 与像元边界对齐的范围不额外扩展
+	c, err := src.Crop(vec2d.Rect{Min: vec2d.T{1020, 2030}, Max: vec2d.T{1050, 2070}})
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{4, 3}, c.Size)
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{1020, 2030}, Max: vec2d.T{1050, 2070}}, c.Extent())
+	assert.Equal(t, 32.0, c.Value(0, 0)) // 北侧第 3 行，第 2 列
+	assert.Equal(t, 64.0, c.Value(3, 2))
+	assert.Equal(t, 10.0, c.CellSize())
+	assert.Equal(t, -9999.0, c.NoData)
+	assert.Equal(t, [4]float64{2070, 2030, 1050, 1020}, c.Bounds)
+
+	// 浮点误差内的对齐
+	c, err = src.Crop(vec2d.Rect{Min: vec2d.T{1020 + 1e-9, 2030 - 1e-9}, Max: vec2d.T{1050 - 1e-9, 2070 + 1e-9}})
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{4, 3}, c.Size)
+
+	// 未对齐的范围向外吸附
+	c, err = src.Crop(vec2d.Rect{Min: vec2d.T{1025, 2035}, Max: vec2d.T{1041, 2061}})
+	assert.NoError(t, err)
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{1020, 2030}, Max: vec2d.T{1050, 2070}}, c.Extent())
+
+	// 部分重叠时裁剪到栅格范围
+	c, err = src.Crop(vec2d.Rect{Min: vec2d.T{1080, 1950}, Max: vec2d.T{1200, 2015}})
+	assert.NoError(t, err)
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{1080, 2000}, Max: vec2d.T{1100, 2020}}, c.Extent())
+	assert.Equal(t, 98.0, c.Value(1, 0))
+	assert.Equal(t, src.ColToX(8), c.ColToX(0))
+	assert.Equal(t, src.RowToY(9), c.RowToY(1))
+
+	// 仅与边界接触视为不相交
+	_, err = src.Crop(vec2d.Rect{Min: vec2d.T{1100, 2000}, Max: vec2d.T{1200, 2100}})
+	assert.Error(t, err)
+}
+
+func TestRasterPad(t *testing.T) {
+	src := createGridRaster()
+	p, err := src.Pad(2, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{14, 14}, p.Size)
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{980, 1980}, Max: vec2d.T{1120, 2120}}, p.Extent())
+	assert.Equal(t, -1.0, p.Value(0, 0))
+	assert.Equal(t, -1.0, p.Value(13, 13))
+	assert.Equal(t, 0.0, p.Value(2, 2))
+	assert.Equal(t, 99.0, p.Value(11, 11))
+	assert.Equal(t, src.ColToX(0), p.ColToX(2))
+	assert.Equal(t, -9999.0, p.NoData)
+
+	_, err = src.Pad(-1, 0)
+	assert.Error(t, err)
+}
+
+func TestMosaic(t *testing.T) {
+	src := createGridRaster()
+	west, _ := src.Crop(vec2d.Rect{Min: vec2d.T{1000, 2000}, Max: vec2d.T{1050, 2100}})
+	east, _ := src.Crop(vec2d.Rect{Min: vec2d.T{1050, 2000}, Max: vec2d.T{1100, 2100}})
+
+	// 边缘相接的两块还原为原栅格
+	m, err := Mosaic(west, east)
+	assert.NoError(t, err)
+	assert.Equal(t, src.Size, m.Size)
+	assert.Equal(t, src.Extent(), m.Extent())
+	assert.Equal(t, src.Data, m.Data)
+
+	// 部分重叠，后者有效值覆盖前者，空缺为 NoData
+	a, _ := src.Crop(vec2d.Rect{Min: vec2d.T{1000, 2050}, Max: vec2d.T{1040, 2100}})
+	b, _ := src.Crop(vec2d.Rect{Min: vec2d.T{1020, 2000}, Max: vec2d.T{1060, 2070}})
+	b.Fill(-5)
+	b.SetValue(0, 0, b.NoData)
+	m, err = Mosaic(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{1000, 2000}, Max: vec2d.T{1060, 2100}}, m.Extent())
+	assert.Equal(t, 0.0, m.Value(0, 0))
+	assert.Equal(t, 32.0, m.Value(3, 2))    // b 的 NoData 不覆盖 a
+	assert.Equal(t, -5.0, m.Value(4, 3))    // 重叠处取 b
+	assert.Equal(t, -9999.0, m.Value(9, 0)) // 两者均未覆盖
+
+	// 未对齐或像元大小不同
+	c := NewRasterDouble(2, 2, -9999)
+	c.SetXYPos(1005, 2000, 10)
+	_, err = Mosaic(src, c)
+	assert.Error(t, err)
+	c.SetXYPos(1000, 2000, 5)
+	_, err = Mosaic(src, c)
+	assert.Error(t, err)
+}
+
+func TestRasterAdapterCrop(t *testing.T) {
+	src := createGridRaster()
+	adapter := NewRasterAdapter(geo.NewMercTileGrid(), nil, src)
+	dem, err := adapter.GetDEM(vec2d.Rect{Min: vec2d.T{1015, 2015}, Max: vec2d.T{1045, 2045}}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{1010, 2010}, Max: vec2d.T{1050, 2050}}, dem.Extent())
+	assert.Equal(t, 10.0, dem.CellSize())
+	assert.Equal(t, 51.0, dem.Value(0, 0))
+	assert.False(t, math.IsNaN(dem.Value(3, 3)))
+}
+
+func TestRasterAdapterNonSquare(t *testing.T) {
+	src := NewRasterDouble(10, 10, -9999)
+	for i := range src.Data {
+		src.Data[i] = float64(i)
+	}
+	src.SetXYPosCellSize(0, 0, 2, 1)
+	adapter := NewRasterAdapter(geo.NewMercTileGrid(), nil, src)
+	dem, err := adapter.GetDEM(vec2d.Rect{Min: vec2d.T{3, 3}, Max: vec2d.T{9, 5}}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, vec2d.Rect{Min: vec2d.T{2, 3}, Max: vec2d.T{10, 5}}, dem.Extent())
+	assert.Equal(t, [2]int{2, 4}, dem.Size)
+	assert.Equal(t, 2.0, dem.CellSizeX())
+	assert.Equal(t, 1.0, dem.CellSizeY())
+	assert.Equal(t, src.Value(5, 1), dem.Value(0, 0))
+}
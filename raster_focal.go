@@ -0,0 +1,291 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// 以中心单元为原点的 (2*Radius+1) x (2*Radius+1) 卷积核，Weights 按行存储
+// 采样时只对有效单元加权，权重按有效单元重新归一化
+// Groups 非空时同组单元先求有效值均值，再以组内权重之和参与加权，组号须在 [0, MaxKernelGroups) 内
+type Kernel struct {
+	Radius  int
+	Weights []float64
+	Groups  []int
+
+	groupWeights []float64 // 预先求出的各组权重之和，为 nil 时采样时累加
+}
+
+// 分组核的最大组数，采样时各组累加值放在栈上
+const MaxKernelGroups = 8
+
+func (k *Kernel) Size() int {
+	return 2*k.Radius + 1
+}
+
+func (k *Kernel) valid() bool {
+	if k.Radius < 0 || len(k.Weights) != k.Size()*k.Size() {
+		return false
+	}
+	if k.Groups == nil {
+		return true
+	}
+	if len(k.Groups) != len(k.Weights) {
+		return false
+	}
+	for _, g := range k.Groups {
+		if g < 0 || g >= MaxKernelGroups {
+			return false
+		}
+	}
+	return true
+}
+
+func NewKernel(radius int, weights []float64) (*Kernel, error) {
+	if radius < 0 {
+		return nil, fmt.Errorf("invalid kernel radius: %d", radius)
+	}
+	if n := (2*radius + 1) * (2*radius + 1); len(weights) != n {
+		return nil, fmt.Errorf("kernel needs %d weights, got %d", n, len(weights))
+	}
+	return &Kernel{Radius: radius, Weights: weights}, nil
+}
+
+// 等权均值核
+func MeanKernel(radius int) *Kernel {
+	n := (2*radius + 1) * (2*radius + 1)
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return &Kernel{Radius: radius, Weights: weights}
+}
+
+// 高斯核，radius <= 0 时取 ceil(3*sigma)
+func GaussianKernel(sigma float64, radius int) *Kernel {
+	if radius <= 0 {
+		radius = max(1, int(math.Ceil(3*sigma)))
+	}
+	size := 2*radius + 1
+	weights := make([]float64, size*size)
+	for dr := -radius; dr <= radius; dr++ {
+		for dc := -radius; dc <= radius; dc++ {
+			weights[(dr+radius)*size+dc+radius] = math.Exp(-float64(dr*dr+dc*dc) / (2 * sigma * sigma))
+		}
+	}
+	return &Kernel{Radius: radius, Weights: weights}
+}
+
+// 返回预先求出组权重的副本，供反复采样同一核时使用，k 须有效
+func (k *Kernel) prepared() *Kernel {
+	if k.Groups == nil {
+		return k
+	}
+	p := *k
+	var gw [MaxKernelGroups]float64
+	groups := 0
+	for i, g := range k.Groups {
+		gw[g] += k.Weights[i]
+		groups = max(groups, g+1)
+	}
+	p.groupWeights = gw[:groups:groups]
+	return &p
+}
+
+// SubSampleRaster3x3 使用的核：中心、十字、对角三组分别平均后按 3:2:1 加权
+var subSample3x3Kernel = (&Kernel{
+	Radius: 1,
+	Weights: []float64{
+		0.25, 0.5, 0.25,
+		0.5, 3, 0.5,
+		0.25, 0.5, 0.25,
+	},
+	Groups: []int{
+		2, 1, 2,
+		1, 0, 1,
+		2, 1, 2,
+	},
+}).prepared()
+
+// 以 (r, c) 为中心用核 k 对栅格加权平均，超出栅格与 NoData 的单元不参与
+// 没有有效单元时返回 NaN
+func SampleKernel(src ElevationRaster, noDataValue float64, k *Kernel, r, c int) float64 {
+	return sampleKernel(src, noDataValue, k, int64(src.Grid().Cols()), int64(src.Grid().Rows()), r, c)
+}
+
+// 只在 w 列 h 行范围内取值
+func sampleKernel(src ElevationRaster, noDataValue float64, k *Kernel, w, h int64, r, c int) float64 {
+	size := k.Size()
+
+	if k.Groups == nil {
+		sum, weight := 0.0, 0.0
+		for i, kw := range k.Weights {
+			if kw == 0 {
+				continue
+			}
+			v := SafeGetPixel(src, w, h, int64(r+i/size-k.Radius), int64(c+i%size-k.Radius))
+			if isNoData(v, noDataValue) {
+				continue
+			}
+			sum += v * kw
+			weight += kw
+		}
+		if weight == 0 {
+			return math.NaN()
+		}
+		return sum / weight
+	}
+
+	// 每组有效值之和与有效单元数
+	var sums [MaxKernelGroups]float64
+	var counts [MaxKernelGroups]int
+	gw := k.groupWeights
+	var local [MaxKernelGroups]float64
+	if gw == nil {
+		groups := 0
+		for i, g := range k.Groups {
+			local[g] += k.Weights[i]
+			groups = max(groups, g+1)
+		}
+		gw = local[:groups]
+	}
+	i := 0
+	for y := r - k.Radius; y <= r+k.Radius; y++ {
+		for x := c - k.Radius; x <= c+k.Radius; x, i = x+1, i+1 {
+			v := SafeGetPixel(src, w, h, int64(y), int64(x))
+			if isNoData(v, noDataValue) {
+				continue
+			}
+			g := k.Groups[i]
+			sums[g] += v
+			counts[g]++
+		}
+	}
+	sum, weight := 0.0, 0.0
+	for g, kw := range gw {
+		if counts[g] == 0 || kw == 0 {
+			continue
+		}
+		sum += sums[g] / float64(counts[g]) * kw
+		weight += kw
+	}
+	if weight == 0 {
+		return math.NaN()
+	}
+	return sum / weight
+}
+
+// 按行并行处理
+func forEachRow(rows int, fn func(r int)) {
+	parallelFor(runtime.GOMAXPROCS(0), rows, fn)
+}
+
+// 以 workers 个协程并行处理 [0, n)，按块分发以减少调度开销
+// workers <= 1 或只有一个元素时在当前协程内顺序执行
+func parallelFor(workers, n int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	chunk := n / (workers * 8)
+	if chunk < 1 {
+		chunk = 1
+	}
+	var next int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				start := int(atomic.AddInt64(&next, int64(chunk))) - chunk
+				if start >= n {
+					return
+				}
+				for i := start; i < start+chunk && i < n; i++ {
+					fn(i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// 用核 k 对栅格做焦点滤波，NoData 单元保持为 NoData
+func FocalFilter(src *RasterDouble, k *Kernel) (*RasterDouble, error) {
+	if src == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	if k == nil || !k.valid() {
+		return nil, fmt.Errorf("invalid kernel")
+	}
+	k = k.prepared()
+	dst := NewRasterDouble(src.Rows(), src.Cols(), src.NoData)
+	dst.copyGeoReference(&src.RasterGrid)
+	forEachRow(src.Rows(), func(r int) {
+		for c := 0; c < src.Cols(); c++ {
+			if isNoData(src.Value(r, c), src.NoData) {
+				continue
+			}
+			dst.SetValue(r, c, SampleKernel(src, src.NoData, k, r, c))
+		}
+	})
+	return dst, nil
+}
+
+// 均值滤波，窗口边长 2*radius+1
+func FocalMean(src *RasterDouble, radius int) (*RasterDouble, error) {
+	if radius < 0 {
+		return nil, fmt.Errorf("invalid kernel radius: %d", radius)
+	}
+	return FocalFilter(src, MeanKernel(radius))
+}
+
+// 高斯滤波，核半径取 ceil(3*sigma)
+func FocalGaussian(src *RasterDouble, sigma float64) (*RasterDouble, error) {
+	if sigma <= 0 {
+		return nil, fmt.Errorf("invalid sigma: %v", sigma)
+	}
+	return FocalFilter(src, GaussianKernel(sigma, 0))
+}
+
+// 中值滤波，窗口内有效单元个数为偶数时取中间两值的均值
+func FocalMedian(src *RasterDouble, radius int) (*RasterDouble, error) {
+	if src == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	if radius < 0 {
+		return nil, fmt.Errorf("invalid kernel radius: %d", radius)
+	}
+	rows, cols := src.Rows(), src.Cols()
+	dst := NewRasterDouble(rows, cols, src.NoData)
+	dst.copyGeoReference(&src.RasterGrid)
+	forEachRow(rows, func(r int) {
+		window := make([]float64, 0, (2*radius+1)*(2*radius+1))
+		for c := 0; c < cols; c++ {
+			if isNoData(src.Value(r, c), src.NoData) {
+				continue
+			}
+			window = window[:0]
+			for y := max(0, r-radius); y <= MinInt(rows-1, r+radius); y++ {
+				for x := max(0, c-radius); x <= MinInt(cols-1, c+radius); x++ {
+					if v := src.Value(y, x); !isNoData(v, src.NoData) {
+						window = append(window, v)
+					}
+				}
+			}
+			sort.Float64s(window)
+			dst.SetValue(r, c, percentileSorted(window, 50))
+		}
+	})
+	return dst, nil
+}
@@ -0,0 +1,89 @@
+package tin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFocalMean(t *testing.T) {
+	r := NewRasterDoubleWithData(3, 3, []float64{
+		1, 2, 3,
+		4, 5, 6,
+		7, 8, math.NaN(),
+	})
+	out, err := FocalMean(r, 1)
+	assert.NoError(t, err)
+	assert.InDelta(t, 4.5, out.Value(1, 1), 1e-12) // NaN 不参与
+	assert.InDelta(t, 3.0, out.Value(0, 0), 1e-12) // 边缘只统计栅格内单元
+	assert.True(t, math.IsNaN(out.Value(2, 2)))    // NoData 保持
+
+	_, err = FocalMean(r, -1)
+	assert.Error(t, err)
+}
+
+func TestFocalMedian(t *testing.T) {
+	r := NewRasterDoubleWithData(3, 3, []float64{
+		1, 1, 1,
+		1, 100, 1,
+		1, 1, 2,
+	})
+	out, err := FocalMedian(r, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, out.Value(1, 1)) // 去除孤立尖峰
+	assert.Equal(t, 1.5, out.Value(2, 2)) // 偶数个有效值取中间两值均值
+}
+
+func TestFocalGaussian(t *testing.T) {
+	// 平面经高斯滤波后保持不变
+	r := NewRasterDouble(9, 9, math.NaN())
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 9; x++ {
+			r.SetValue(y, x, float64(2*x+y))
+		}
+	}
+	out, err := FocalGaussian(r, 1)
+	assert.NoError(t, err)
+	assert.InDelta(t, r.Value(4, 4), out.Value(4, 4), 1e-9)
+
+	k := GaussianKernel(1, 0)
+	assert.Equal(t, 3, k.Radius)
+	assert.Greater(t, k.Weights[k.Radius*k.Size()+k.Radius], k.Weights[0])
+
+	_, err = FocalGaussian(r, 0)
+	assert.Error(t, err)
+}
+
+func TestSampleKernel(t *testing.T) {
+	r := NewRasterDoubleWithData(3, 3, []float64{
+		1, 2, 3,
+		4, 5, 6,
+		7, 8, 9,
+	})
+	k, err := NewKernel(1, []float64{
+		0, 1, 0,
+		1, 0, 1,
+		0, 1, 0,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 5.0, SampleKernel(r, math.NaN(), k, 1, 1), 1e-12)
+	assert.InDelta(t, 3.0, SampleKernel(r, math.NaN(), k, 0, 0), 1e-12)
+
+	// 分组核与 SubSampleRaster3x3 一致，未预先求组权重的核结果相同
+	grouped := &Kernel{Radius: 1, Weights: subSample3x3Kernel.Weights, Groups: subSample3x3Kernel.Groups}
+	for _, rc := range [][2]int{{0, 1}, {1, 1}, {2, 2}} {
+		want := SubSampleRaster3x3(r, math.NaN(), 3, 3, int64(rc[0]), int64(rc[1]))
+		assert.InDelta(t, want, SampleKernel(r, math.NaN(), subSample3x3Kernel, rc[0], rc[1]), 1e-12)
+		assert.InDelta(t, want, SampleKernel(r, math.NaN(), grouped, rc[0], rc[1]), 1e-12)
+	}
+
+	// 组号超出 MaxKernelGroups 的核无效
+	groups := make([]int, 9)
+	groups[4] = MaxKernelGroups
+	_, err = FocalFilter(r, &Kernel{Radius: 1, Weights: grouped.Weights, Groups: groups})
+	assert.Error(t, err)
+
+	_, err = NewKernel(1, []float64{1})
+	assert.Error(t, err)
+}
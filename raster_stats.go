@@ -0,0 +1,123 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 栅格统计结果，NoData 单元不参与统计，无有效单元时 Min/Max/Mean/StdDev 为 NaN
+type RasterStats struct {
+	Count       int // 有效单元数
+	NoDataCount int
+	Min         float64
+	Max         float64
+	Mean        float64
+	StdDev      float64 // 总体标准差
+}
+
+func (s *RasterStats) String() string {
+	return fmt.Sprintf("count=%d nodata=%d min=%.4f max=%.4f mean=%.4f stddev=%.4f",
+		s.Count, s.NoDataCount, s.Min, s.Max, s.Mean, s.StdDev)
+}
+
+// 统计栅格有效单元的最值、均值与标准差
+func ComputeStats(src *RasterDouble) *RasterStats {
+	s := &RasterStats{Min: math.Inf(1), Max: math.Inf(-1)}
+	noDataValue := src.NoData
+	sum := 0.0
+	for _, v := range src.Data {
+		if isNoData(v, noDataValue) {
+			s.NoDataCount++
+			continue
+		}
+		s.Count++
+		sum += v
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+	if s.Count == 0 {
+		s.Min, s.Max, s.Mean, s.StdDev = math.NaN(), math.NaN(), math.NaN(), math.NaN()
+		return s
+	}
+	s.Mean = sum / float64(s.Count)
+
+	// 两遍计算方差，避免大高程值下的精度损失
+	sumSq := 0.0
+	for _, v := range src.Data {
+		if !isNoData(v, noDataValue) {
+			d := v - s.Mean
+			sumSq += d * d
+		}
+	}
+	s.StdDev = math.Sqrt(sumSq / float64(s.Count))
+	return s
+}
+
+// 等宽直方图，区间为 [Min, Max]，最后一个分箱包含 Max
+type Histogram struct {
+	Min    float64
+	Max    float64
+	Counts []int
+}
+
+func (h *Histogram) BinWidth() float64 {
+	return (h.Max - h.Min) / float64(len(h.Counts))
+}
+
+// 第 i 个分箱的下界
+func (h *Histogram) BinMin(i int) float64 {
+	return h.Min + float64(i)*h.BinWidth()
+}
+
+// 统计 [lo, hi] 内有效单元的直方图，lo >= hi 时取栅格的值域
+func ComputeHistogram(src *RasterDouble, bins int, lo, hi float64) (*Histogram, error) {
+	if bins <= 0 {
+		return nil, fmt.Errorf("invalid bin count: %d", bins)
+	}
+	if lo >= hi {
+		s := ComputeStats(src)
+		if s.Count == 0 {
+			return nil, fmt.Errorf("no valid cells")
+		}
+		lo, hi = s.Min, s.Max
+		if lo == hi {
+			hi = lo + 1
+		}
+	}
+
+	h := &Histogram{Min: lo, Max: hi, Counts: make([]int, bins)}
+	noDataValue := src.NoData
+	scale := float64(bins) / (hi - lo)
+	for _, v := range src.Data {
+		if isNoData(v, noDataValue) || v < lo || v > hi {
+			continue
+		}
+		h.Counts[MinInt(int((v-lo)*scale), bins-1)]++
+	}
+	return h, nil
+}
+
+// 有效单元的百分位数，p 取值 0-100，相邻秩之间线性插值
+func Percentiles(src *RasterDouble, ps ...float64) ([]float64, error) {
+	noDataValue := src.NoData
+	values := make([]float64, 0, len(src.Data))
+	for _, v := range src.Data {
+		if !isNoData(v, noDataValue) {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no valid cells")
+	}
+	sort.Float64s(values)
+
+	result := make([]float64, len(ps))
+	for i, p := range ps {
+		if p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile: %v", p)
+		}
+		result[i] = percentileSorted(values, p)
+	}
+	return result, nil
+}
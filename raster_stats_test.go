@@ -0,0 +1,56 @@
+package tin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeStats(t *testing.T) {
+	r := NewRasterDoubleWithData(2, 3, []float64{1, 2, 3, 4, -9999, 6})
+	r.NoData = -9999
+
+	s := ComputeStats(r)
+	assert.Equal(t, 5, s.Count)
+	assert.Equal(t, 1, s.NoDataCount)
+	assert.Equal(t, 1.0, s.Min)
+	assert.Equal(t, 6.0, s.Max)
+	assert.InDelta(t, 3.2, s.Mean, 1e-12)
+	assert.InDelta(t, math.Sqrt(2.96), s.StdDev, 1e-12)
+
+	empty := ComputeStats(NewRasterDouble(2, 2, math.NaN()))
+	assert.Equal(t, 0, empty.Count)
+	assert.True(t, math.IsNaN(empty.Mean))
+}
+
+func TestComputeHistogram(t *testing.T) {
+	r := NewRasterDoubleWithData(2, 3, []float64{0, 1, 2, 3, 4, math.NaN()})
+
+	h, err := ComputeHistogram(r, 2, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, h.Counts) // 最大值落入最后一个分箱
+	assert.Equal(t, 2.0, h.BinMin(1))
+
+	h, err = ComputeHistogram(r, 4, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 0, 1, 1}, h.Counts)
+
+	_, err = ComputeHistogram(r, 0, 0, 1)
+	assert.Error(t, err)
+	_, err = ComputeHistogram(NewRasterDouble(2, 2, math.NaN()), 4, 0, 0)
+	assert.Error(t, err)
+}
+
+func TestPercentiles(t *testing.T) {
+	r := NewRasterDoubleWithData(1, 6, []float64{5, 1, math.NaN(), 4, 2, 3})
+
+	ps, err := Percentiles(r, 0, 50, 75, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 3, 4, 5}, ps)
+
+	_, err = Percentiles(r, 101)
+	assert.Error(t, err)
+	_, err = Percentiles(NewRasterDouble(1, 1, math.NaN()), 50)
+	assert.Error(t, err)
+}
@@ -141,6 +141,14 @@ func TestRasterCoordinateTransformation(t *testing.T) {
 	if row := r.YToRow(250.1); row != 0 {
 		t.Errorf("YToRow 边界外处理错误: 预期 0, 实际 %d", row)
 	}
+
+	// 有旋转项时无法只由单个坐标确定行列号
+	gt := r.GeoTransform()
+	gt[2], gt[4] = 0.5, 0.5
+	r.SetGeoTransform(gt)
+	if col, row := r.XToCol(150), r.YToRow(220); col != -1 || row != -1 {
+		t.Errorf("旋转栅格应返回 -1, 实际列 %d 行 %d", col, row)
+	}
 }
 
 // 修复后的栅格坐标转换逻辑
@@ -401,3 +409,86 @@ func TestNoDataHandling(t *testing.T) {
 		t.Error("无效值坐标转换处理错误")
 	}
 }
+
+// 测试像元类型转换
+func TestConvertRaster(t *testing.T) {
+	// int16 DEM，以 0.1 米为单位，偏移 -100 米
+	src := NewRasterOfWithData(2, 2, []int16{0, 1234, math.MinInt16, -5})
+	src.SetXYPos(10, 20, 30)
+	if src.NoData != math.MinInt16 {
+		t.Fatalf("int16 默认无效值错误: %d", src.NoData)
+	}
+
+	dem := ToRasterDouble(src, 0.1, -100)
+	if dem.CellSize() != 30 || dem.West() != 10 {
+		t.Error("应保留地理参考信息")
+	}
+	want := []float64{-100, 23.4, math.NaN(), -100.5}
+	for i, v := range dem.DataSlice() {
+		if math.IsNaN(want[i]) != math.IsNaN(v) || (!math.IsNaN(v) && math.Abs(v-want[i]) > 1e-9) {
+			t.Errorf("位置 %d: 预期 %.4f, 实际 %.4f", i, want[i], v)
+		}
+	}
+
+	// 反向转换：四舍五入并截断到 int16 取值范围
+	dem.SetValue(0, 0, 1e9)
+	back := ConvertRaster(dem, 10, 1000, int16(-9999))
+	wantBack := []int16{math.MaxInt16, 1234, -9999, -5}
+	for i, v := range back.DataSlice() {
+		if v != wantBack[i] {
+			t.Errorf("位置 %d: 预期 %d, 实际 %d", i, wantBack[i], v)
+		}
+	}
+
+	f32 := ConvertRaster(dem, 1, 0, float32(math.NaN()))
+	if f32.Type() != RASTER_DATA_TYPE_FLOAT32 || !f32.IsNoData(f32.Value(1, 0)) || f32.Value(0, 1) != float32(23.4) {
+		t.Errorf("float32 转换错误: %v", f32.DataSlice())
+	}
+}
+
+// 测试越界与 NaN 的像元值转换
+func TestToElement(t *testing.T) {
+	if v, ok := toElement[int16](math.NaN()); ok {
+		t.Errorf("NaN 不应转换为 int16: %d", v)
+	}
+	if v, _ := toElement[int8](math.Inf(1)); v != math.MaxInt8 {
+		t.Errorf("+Inf 应截断到 int8 上界: %d", v)
+	}
+	if v, _ := toElement[uint8](-3.7); v != 0 {
+		t.Errorf("负数应截断到 uint8 下界: %d", v)
+	}
+	if v, _ := toElement[int64](1e19); v != math.MaxInt64 {
+		t.Errorf("int64 上界错误: %d", v)
+	}
+	if v, _ := toElement[uint64](math.Inf(1)); v != math.MaxUint64 {
+		t.Errorf("uint64 上界错误: %d", v)
+	}
+	if v, _ := toElement[int32](-2.5); v != -3 {
+		t.Errorf("应四舍五入: %d", v)
+	}
+
+	// 源栅格中非无效值的 NaN 转换为目标无效值
+	src := NewRasterDoubleWithData(1, 3, []float64{math.NaN(), math.Inf(-1), 1.4})
+	src.NoData = -9999
+	dst := ConvertRaster(src, 1, 0, int16(-1))
+	if got := dst.DataSlice(); got[0] != -1 || got[1] != math.MinInt16 || got[2] != 1 {
+		t.Errorf("转换结果错误: %v", got)
+	}
+	dst.SetElevation(0, 2, math.NaN())
+	if dst.Value(0, 2) != -1 {
+		t.Errorf("NaN 应写为无效值: %d", dst.Value(0, 2))
+	}
+}
+
+// 测试泛型栅格内嵌的无类型视图
+func TestRasterUntypedView(t *testing.T) {
+	r := NewRasterOf(2, 2, int16(-1))
+	r.SetValue(1, 0, 7)
+	if r.Raster.Value(1, 0) != int16(7) || r.Raster.NoData != int16(-1) || r.Raster.Type != RASTER_DATA_TYPE_INT16 {
+		t.Errorf("无类型视图错误: %v %v %v", r.Raster.Value(1, 0), r.Raster.NoData, r.Raster.Type)
+	}
+	r.Raster.SetValue(0, 1, int16(3))
+	if r.Value(0, 1) != 3 {
+		t.Errorf("视图应与 Data 共用数组: %d", r.Value(0, 1))
+	}
+}
@@ -41,65 +41,32 @@ func AverageNanArr(toAverage []float64) float64 {
 	return sum / float64(avgCount)
 }
 
-func SafeGetPixel(src *RasterDouble, w, h, r, c int64) float64 {
+func SafeGetPixel(src ElevationRaster, w, h, r, c int64) float64 {
 	if r >= 0 && r < h && c >= 0 && c < w {
-		return src.Value(int(r), int(c))
+		return src.Elevation(int(r), int(c))
 	}
 	return math.NaN()
 }
 
-func SubSampleRaster3x3(src *RasterDouble, noDataValue float64, w, h, r, c int64) float64 {
-	var centerPixel float64
-	var crossPixels [4]float64
-	var diagPixels [4]float64
-
-	diagPixels[0] = SafeGetPixel(src, w, h, r-1, c-1) // top-left
-	crossPixels[0] = SafeGetPixel(src, w, h, r-1, c)  // top
-	diagPixels[1] = SafeGetPixel(src, w, h, r-1, c+1) // top-right
-	crossPixels[1] = SafeGetPixel(src, w, h, r, c-1)  // center-left
-	centerPixel = SafeGetPixel(src, w, h, r, c)       // center-center
-	crossPixels[2] = SafeGetPixel(src, w, h, r, c+1)  // center-right
-	diagPixels[2] = SafeGetPixel(src, w, h, r+1, c-1) // bottom-left
-	crossPixels[3] = SafeGetPixel(src, w, h, r+1, c)  // bottom-center
-	diagPixels[3] = SafeGetPixel(src, w, h, r+1, c+1) // bottom-right
-
-	if centerPixel == noDataValue {
-		centerPixel = math.NaN()
-	}
-
-	for i := 0; i < 4; i++ {
-		if diagPixels[i] == noDataValue {
-			diagPixels[i] = math.NaN()
-		}
-		if crossPixels[i] == noDataValue {
-			crossPixels[i] = math.NaN()
-		}
-	}
-
-	crossAvg := AverageNanArr(crossPixels[:])
-	diagAvg := AverageNanArr(diagPixels[:])
-
-	weighted := [6]float64{
-		centerPixel, centerPixel, centerPixel, crossAvg, crossAvg, diagAvg,
-	}
-	weightedAvg := AverageNanArr(weighted[:])
-	return weightedAvg
+// 3x3 邻域加权平均：中心、十字、对角三组分别求有效值均值后按 3:2:1 加权
+func SubSampleRaster3x3(src ElevationRaster, noDataValue float64, w, h, r, c int64) float64 {
+	return sampleKernel(src, noDataValue, subSample3x3Kernel, w, h, int(r), int(c))
 }
 
-func SampleNearestValidAvg(src *RasterDouble, _row, _column int, minAveragingSamples int) float64 {
+func SampleNearestValidAvg(src ElevationRaster, _row, _column int, minAveragingSamples int) float64 {
 	minAveragingSamples = MinInt(minAveragingSamples, MAX_AVERAGING_SAMPLES)
 
 	row := _row
 	column := _column
-	w := src.Cols()
-	h := src.Rows()
+	w := src.Grid().Cols()
+	h := src.Grid().Rows()
 
 	maxRadius := int64(math.Sqrt(float64(w*w + h*h)))
-	noDataValue := src.NoData.(float64)
+	noDataValue := math.NaN()
 
 	z := float64(0)
 	if row < h && column < w {
-		z = src.Value(row, column)
+		z = src.Elevation(row, column)
 	}
 	if !isNoData(z, noDataValue) {
 		return z
@@ -160,3 +127,47 @@ func SampleNearestValidAvg(src *RasterDouble, _row, _column int, minAveragingSam
 
 	return average(toAverage, avgCount)
 }
+
+// 按像素坐标双线性插值，整数坐标对应像元中心
+// NoData 角点不参与加权，四个角点均无效或超出栅格时返回 NaN
+func SampleBilinear(src *RasterDouble, row, col float64) float64 {
+	h, w := src.Rows(), src.Cols()
+	if row < -0.5 || col < -0.5 || row > float64(h)-0.5 || col > float64(w)-0.5 {
+		return math.NaN()
+	}
+	noDataValue := src.NoData
+
+	r0 := int(math.Floor(row))
+	c0 := int(math.Floor(col))
+	fr := row - float64(r0)
+	fc := col - float64(c0)
+
+	sum, weight := 0.0, 0.0
+	for dr := 0; dr <= 1; dr++ {
+		for dc := 0; dc <= 1; dc++ {
+			wr := 1 - fr
+			if dr == 1 {
+				wr = fr
+			}
+			wc := 1 - fc
+			if dc == 1 {
+				wc = fc
+			}
+			if wr*wc == 0 {
+				continue
+			}
+			r := MinInt(max(r0+dr, 0), h-1)
+			c := MinInt(max(c0+dc, 0), w-1)
+			v := src.Value(r, c)
+			if isNoData(v, noDataValue) {
+				continue
+			}
+			sum += v * wr * wc
+			weight += wr * wc
+		}
+	}
+	if weight == 0 {
+		return math.NaN()
+	}
+	return sum / weight
+}
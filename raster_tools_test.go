@@ -125,3 +125,13 @@ func TestSubSampleRaster3x3(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkSubSampleRaster3x3(b *testing.B) {
+	src := createWaveRaster()
+	w, h := int64(src.Cols()), int64(src.Rows())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SubSampleRaster3x3(src, math.NaN(), w, h, int64(i%src.Rows()), int64(i/src.Rows()%src.Cols()))
+	}
+}
@@ -0,0 +1,76 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/flywave/go-geo"
+	vec2d "github.com/flywave/go3d/float64/vec2"
+)
+
+// 将 Mesh 栅格化到由 bbox、cellSize 定义的目标网格
+// srs 为目标坐标系，与 Mesh.GeoRef 坐标系不同时先对顶点做投影变换，为 nil 时不变换
+// 网格未覆盖的单元为 NoData (NaN)
+func RasterizeMesh(mesh *Mesh, bbox vec2d.Rect, cellSize float64, srs geo.Proj) (*RasterDouble, error) {
+	if mesh == nil {
+		return nil, fmt.Errorf("nil mesh")
+	}
+	if cellSize <= 0 {
+		return nil, fmt.Errorf("invalid cellsize: %.6f", cellSize)
+	}
+
+	cols := int(math.Ceil(bbox.Width()/cellSize - EPS))
+	rows := int(math.Ceil(bbox.Height()/cellSize - EPS))
+	if cols <= 0 || rows <= 0 {
+		return nil, fmt.Errorf("invalid raster size: %dx%d", rows, cols)
+	}
+
+	target := NewRasterDouble(rows, cols, math.NaN())
+	target.SetXYPos(bbox.Min[0], bbox.Min[1], cellSize)
+
+	var transform func(x, y float64) (float64, float64)
+	if srs != nil && mesh.GeoRef != nil && mesh.GeoRef.GetSrs() != nil && !mesh.GeoRef.GetSrs().Eq(srs) {
+		srcProj := mesh.GeoRef.GetSrs()
+		transform = func(x, y float64) (float64, float64) {
+			pt, _ := transformPoint(srcProj, srs, x, y)
+			return pt[0], pt[1]
+		}
+	}
+
+	rasterizeMesh(mesh, target, transform)
+	return target, nil
+}
+
+// 将 Mesh 栅格化到已有栅格上，只写入网格覆盖的单元，其余单元保持原值
+// Mesh 顶点须与目标栅格处于同一坐标系
+func RasterizeMeshTo(mesh *Mesh, target *RasterDouble) error {
+	if mesh == nil || target == nil {
+		return fmt.Errorf("nil mesh or raster")
+	}
+	if target.CellSize() <= 0 {
+		return fmt.Errorf("invalid cellsize: %.6f", target.CellSize())
+	}
+	rasterizeMesh(mesh, target, nil)
+	return nil
+}
+
+// 逐三角形按扫描线写入重心插值高程
+func rasterizeMesh(mesh *Mesh, target *RasterDouble, transform func(x, y float64) (float64, float64)) {
+	rows, cols := target.Rows(), target.Cols()
+	mesh.GenerateTriangles()
+
+	for _, t := range mesh.Triangles {
+		var p [3][3]float64
+		for i := range t {
+			x, y := t[i][0], t[i][1]
+			if transform != nil {
+				x, y = transform(x, y)
+			}
+			p[i][0], p[i][1] = target.geoToPixel(x, y)
+			p[i][2] = t[i][2]
+		}
+		scanTriangleCells(p, rows, cols, func(row, col int, z float64) {
+			target.SetValue(row, col, z)
+		})
+	}
+}
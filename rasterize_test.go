@@ -0,0 +1,77 @@
+package tin
+
+import (
+	"math"
+	"testing"
+
+	vec2d "github.com/flywave/go3d/float64/vec2"
+)
+
+func TestRasterizeMesh(t *testing.T) {
+	// 覆盖 [0,4]x[0,4] 的单个三角形，高程 z = x + 2y
+	mesh := &Mesh{}
+	mesh.InitFromTriangles([]Triangle{
+		{{0, 0, 0}, {4, 0, 4}, {0, 4, 8}},
+	})
+	mesh.GenerateDecomposed()
+
+	bbox := vec2d.Rect{Min: vec2d.T{0, 0}, Max: vec2d.T{4, 4}}
+	r, err := RasterizeMesh(mesh, bbox, 1.0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Rows() != 4 || r.Cols() != 4 {
+		t.Fatalf("尺寸错误: 预期 4x4, 实际 %dx%d", r.Rows(), r.Cols())
+	}
+	if r.West() != 0 || r.South() != 0 || r.East() != 4 || r.North() != 4 {
+		t.Errorf("边界错误: %v", r.Bounds)
+	}
+
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			x, y := r.ColToX(col), r.RowToY(row)
+			v := r.Value(row, col)
+			if x+y <= 4 {
+				if math.Abs(v-(x+2*y)) > 1e-9 {
+					t.Errorf("单元 (%d,%d) 插值错误: 预期 %.2f, 实际 %.2f", row, col, x+2*y, v)
+				}
+			} else if !math.IsNaN(v) {
+				t.Errorf("单元 (%d,%d) 位于网格外应为 NaN, 实际 %.2f", row, col, v)
+			}
+		}
+	}
+
+	if _, err := RasterizeMesh(mesh, bbox, 0, nil); err == nil {
+		t.Error("cellsize 为 0 时应返回错误")
+	}
+}
+
+func TestRasterizeZemlyaMesh(t *testing.T) {
+	src := createWaveRaster()
+	z := NewZemlyaMesh(&GeoConfig{})
+	z.LoadRaster(src)
+	z.GreedyInsert(0.2)
+	mesh := z.ToMesh()
+
+	// 栅格化回原始网格，与精度评估的结果一致
+	target := NewRasterDouble(src.Rows(), src.Cols(), math.NaN())
+	target.copyGeoReference(&src.RasterGrid)
+	if err := RasterizeMeshTo(mesh, target); err != nil {
+		t.Fatal(err)
+	}
+
+	maxErr := 0.0
+	for row := 0; row < src.Rows(); row++ {
+		for col := 0; col < src.Cols(); col++ {
+			v := target.Value(row, col)
+			if math.IsNaN(v) {
+				t.Fatalf("单元 (%d,%d) 未被覆盖", row, col)
+			}
+			maxErr = math.Max(maxErr, math.Abs(v-src.Value(row, col)))
+		}
+	}
+	if math.Abs(maxErr-z.AchievedError) > 1e-6 {
+		t.Errorf("栅格化误差与 AchievedError 不一致: %.6f != %.6f", maxErr, z.AchievedError)
+	}
+}
@@ -0,0 +1,336 @@
+package tin
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"sort"
+)
+
+// 光照方向向量，方位角自正北顺时针，高度角自地平面起算，单位为度
+func lightVector(azimuth, altitude float64) [3]float64 {
+	az := azimuth * math.Pi / 180
+	alt := altitude * math.Pi / 180
+	return [3]float64{math.Sin(az) * math.Cos(alt), math.Cos(az) * math.Cos(alt), math.Sin(alt)}
+}
+
+// 由梯度计算朗伯光照强度，取值 0-1
+func shadeGradient(dzdx, dzdy float64, light [3]float64) float64 {
+	n := math.Sqrt(dzdx*dzdx + dzdy*dzdy + 1)
+	return math.Max(0, (-dzdx*light[0]-dzdy*light[1]+light[2])/n)
+}
+
+// 使用 Horn 算法计算山体阴影，结果取值 0-255，NoData 单元为 NaN
+// 缺失的邻域单元用中心单元高程代替
+func Hillshade(dem *RasterDouble, azimuth, altitude, zFactor float64) (*RasterDouble, error) {
+	if dem == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	if dem.minCellSize() <= 0 {
+		return nil, fmt.Errorf("invalid cellsize: %.6f", dem.minCellSize())
+	}
+	// 带符号的像元大小，南向上栅格 Y 方向为正
+	gt := dem.GeoTransform()
+	sizeX, sizeY := gt[1], gt[5]
+	if zFactor <= 0 {
+		zFactor = 1
+	}
+	rows, cols := dem.Rows(), dem.Cols()
+	noDataValue := dem.NoData
+	light := lightVector(azimuth, altitude)
+
+	shade := NewRasterDouble(rows, cols, math.NaN())
+	shade.copyGeoReference(&dem.RasterGrid)
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			z0 := dem.Value(r, c)
+			if isNoData(z0, noDataValue) {
+				continue
+			}
+			var w [3][3]float64
+			for dr := -1; dr <= 1; dr++ {
+				for dc := -1; dc <= 1; dc++ {
+					v := SafeGetPixel(dem, int64(cols), int64(rows), int64(r+dr), int64(c+dc))
+					if isNoData(v, noDataValue) {
+						v = z0
+					}
+					w[dr+1][dc+1] = v * zFactor
+				}
+			}
+			dzdx := ((w[0][2] + 2*w[1][2] + w[2][2]) - (w[0][0] + 2*w[1][0] + w[2][0])) / (8 * sizeX)
+			dzdy := ((w[2][0] + 2*w[2][1] + w[2][2]) - (w[0][0] + 2*w[0][1] + w[0][2])) / (8 * sizeY)
+			shade.SetValue(r, c, 255*shadeGradient(dzdx, dzdy, light))
+		}
+	}
+	return shade, nil
+}
+
+// 色带节点，Value 为 0-1 的相对高程
+type ColorStop struct {
+	Value float64
+	Color color.RGBA
+}
+
+// 按相对高程线性插值的色带
+type ColorRamp []ColorStop
+
+// 自低到高：蓝、绿、黄、棕、白
+var DefaultColorRamp = ColorRamp{
+	{0, color.RGBA{0, 0, 140, 255}},
+	{0.15, color.RGBA{0, 160, 80, 255}},
+	{0.45, color.RGBA{220, 220, 90, 255}},
+	{0.75, color.RGBA{140, 90, 50, 255}},
+	{1, color.RGBA{255, 255, 255, 255}},
+}
+
+func (cr ColorRamp) At(t float64) color.RGBA {
+	if len(cr) == 0 {
+		g := uint8(math.Round(255 * math.Min(1, math.Max(0, t))))
+		return color.RGBA{g, g, g, 255}
+	}
+	if t <= cr[0].Value {
+		return cr[0].Color
+	}
+	i := sort.Search(len(cr), func(i int) bool { return cr[i].Value >= t })
+	if i == len(cr) {
+		return cr[len(cr)-1].Color
+	}
+	a, b := cr[i-1], cr[i]
+	f := (t - a.Value) / (b.Value - a.Value)
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + f*(float64(y)-float64(x))))
+	}
+	return color.RGBA{lerp(a.Color.R, b.Color.R), lerp(a.Color.G, b.Color.G), lerp(a.Color.B, b.Color.B), lerp(a.Color.A, b.Color.A)}
+}
+
+// 渲染选项
+type RenderOptions struct {
+	Width      int // 图像宽度，默认 512
+	Height     int // 图像高度，为 0 时按范围长宽比计算
+	Ramp       ColorRamp
+	Shaded     bool    // 是否叠加光照
+	Azimuth    float64 // 光照方位角，默认 315
+	Altitude   float64 // 光照高度角，默认 45
+	ZFactor    float64
+	Wireframe  bool
+	WireColor  color.RGBA
+	Background color.RGBA
+}
+
+func DefaultRenderOptions() *RenderOptions {
+	return &RenderOptions{
+		Width:     512,
+		Ramp:      DefaultColorRamp,
+		Shaded:    true,
+		Azimuth:   315,
+		Altitude:  45,
+		ZFactor:   1,
+		WireColor: color.RGBA{0, 0, 0, 255},
+	}
+}
+
+func (o *RenderOptions) normalize() *RenderOptions {
+	if o == nil {
+		return DefaultRenderOptions()
+	}
+	opts := *o
+	if opts.Width <= 0 {
+		opts.Width = 512
+	}
+	if opts.ZFactor <= 0 {
+		opts.ZFactor = 1
+	}
+	if opts.Azimuth == 0 && opts.Altitude == 0 {
+		opts.Azimuth, opts.Altitude = 315, 45
+	}
+	return &opts
+}
+
+// 环境光比例，避免背光面完全变黑
+const renderAmbient = 0.25
+
+func shadeColor(c color.RGBA, f float64) color.RGBA {
+	f = renderAmbient + (1-renderAmbient)*f
+	return color.RGBA{uint8(float64(c.R) * f), uint8(float64(c.G) * f), uint8(float64(c.B) * f), c.A}
+}
+
+// 自顶向下正射渲染 Mesh，按色带着色，可选光照与线框
+func RenderMesh(mesh *Mesh, opts *RenderOptions) (*image.RGBA, error) {
+	if mesh == nil {
+		return nil, fmt.Errorf("nil mesh")
+	}
+	opts = opts.normalize()
+	if !mesh.hasDecomposed() {
+		mesh.GenerateDecomposed()
+	}
+	if len(mesh.Faces) == 0 {
+		return nil, fmt.Errorf("empty mesh")
+	}
+
+	bbox := NewBBox3d()
+	for _, v := range mesh.Vertices {
+		bbox.add(v[:])
+	}
+	bw, bh := bbox.Width(), bbox.Height()
+	if bw <= 0 || bh <= 0 {
+		return nil, fmt.Errorf("degenerate mesh extent: %.6f x %.6f", bw, bh)
+	}
+	w := opts.Width
+	h := opts.Height
+	if h <= 0 {
+		h = max(1, int(math.Round(float64(w)*bh/bw)))
+	}
+	sx, sy := float64(w)/bw, float64(h)/bh
+	minZ, depth := bbox[2], bbox.Depth()
+
+	// 地理坐标转换为像素坐标，图像行向下
+	toPixel := func(v Vertex) [2]float64 {
+		return [2]float64{(v[0] - bbox[0]) * sx, (bbox[4] - v[1]) * sy}
+	}
+	relZ := func(z float64) float64 {
+		if depth <= 0 {
+			return 0.5
+		}
+		return (z - minZ) / depth
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = opts.Background.R, opts.Background.G, opts.Background.B, opts.Background.A
+	}
+	light := lightVector(opts.Azimuth, opts.Altitude)
+
+	for _, f := range mesh.Faces {
+		v := [3]Vertex{mesh.Vertices[f[0]], mesh.Vertices[f[1]], mesh.Vertices[f[2]]}
+		p := [3][2]float64{toPixel(v[0]), toPixel(v[1]), toPixel(v[2])}
+		if Orientation(p[0], p[1], p[2]) == 0 {
+			continue
+		}
+		shade := 1.0
+		if opts.Shaded {
+			zs := [3][3]float64{v[0], v[1], v[2]}
+			for i := range zs {
+				zs[i][2] *= opts.ZFactor
+			}
+			plane := NewPlane(zs[0], zs[1], zs[2])
+			shade = shadeGradient(plane[0], plane[1], light)
+		}
+
+		x0 := max(0, int(math.Floor(math.Min(p[0][0], math.Min(p[1][0], p[2][0])))))
+		x1 := MinInt(w-1, int(math.Ceil(math.Max(p[0][0], math.Max(p[1][0], p[2][0])))))
+		y0 := max(0, int(math.Floor(math.Min(p[0][1], math.Min(p[1][1], p[2][1])))))
+		y1 := MinInt(h-1, int(math.Ceil(math.Max(p[0][1], math.Max(p[1][1], p[2][1])))))
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				l0, l1, l2, inside := barycentric(p[0], p[1], p[2], [2]float64{float64(x) + 0.5, float64(y) + 0.5})
+				if !inside {
+					continue
+				}
+				z := l0*v[0][2] + l1*v[1][2] + l2*v[2][2]
+				img.SetRGBA(x, y, shadeColor(opts.Ramp.At(relZ(z)), shade))
+			}
+		}
+	}
+
+	if opts.Wireframe {
+		for _, f := range mesh.Faces {
+			for i := range f {
+				drawLine(img, toPixel(mesh.Vertices[f[i]]), toPixel(mesh.Vertices[f[(i+1)%3]]), opts.WireColor)
+			}
+		}
+	}
+	return img, nil
+}
+
+// DDA 画线
+func drawLine(img *image.RGBA, a, b [2]float64, c color.RGBA) {
+	n := int(math.Ceil(math.Max(math.Abs(b[0]-a[0]), math.Abs(b[1]-a[1]))))
+	for i := 0; i <= n; i++ {
+		t := 0.0
+		if n > 0 {
+			t = float64(i) / float64(n)
+		}
+		x := int(math.Floor(a[0] + t*(b[0]-a[0])))
+		y := int(math.Floor(a[1] + t*(b[1]-a[1])))
+		if (image.Point{x, y}).In(img.Rect) {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// 按单元渲染 DEM，每个单元对应一个像素，NoData 为背景色
+// Shaded 为 true 时与山体阴影叠加
+func RenderRaster(dem *RasterDouble, opts *RenderOptions) (*image.RGBA, error) {
+	if dem == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	opts = opts.normalize()
+	rows, cols := dem.Rows(), dem.Cols()
+	noDataValue := dem.NoData
+
+	minZ, maxZ := math.Inf(1), math.Inf(-1)
+	for _, v := range dem.DataSlice() {
+		if !isNoData(v, noDataValue) {
+			minZ = math.Min(minZ, v)
+			maxZ = math.Max(maxZ, v)
+		}
+	}
+
+	var shade *RasterDouble
+	if opts.Shaded {
+		var err error
+		if shade, err = Hillshade(dem, opts.Azimuth, opts.Altitude, opts.ZFactor); err != nil {
+			return nil, err
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cols, rows))
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			z := dem.Value(r, c)
+			if isNoData(z, noDataValue) {
+				img.SetRGBA(c, r, opts.Background)
+				continue
+			}
+			t := 0.5
+			if maxZ > minZ {
+				t = (z - minZ) / (maxZ - minZ)
+			}
+			col := opts.Ramp.At(t)
+			if shade != nil {
+				col = shadeColor(col, shade.Value(r, c)/255)
+			}
+			img.SetRGBA(c, r, col)
+		}
+	}
+	return img, nil
+}
+
+func writePNG(filename string, img image.Image) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %v", err)
+	}
+	defer file.Close()
+	return png.Encode(file, img)
+}
+
+func ExportMeshPNG(filename string, mesh *Mesh, opts *RenderOptions) error {
+	img, err := RenderMesh(mesh, opts)
+	if err != nil {
+		return err
+	}
+	return writePNG(filename, img)
+}
+
+func ExportRasterPNG(filename string, dem *RasterDouble, opts *RenderOptions) error {
+	img, err := RenderRaster(dem, opts)
+	if err != nil {
+		return err
+	}
+	return writePNG(filename, img)
+}
@@ -0,0 +1,153 @@
+package tin
+
+import (
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHillshade(t *testing.T) {
+	flat := NewRasterDouble(5, 5, math.NaN())
+	flat.Fill(10)
+	flat.SetXYPos(0, 0, 1)
+	shade, err := Hillshade(flat, 315, 45, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := 255 * math.Sin(math.Pi/4)
+	for _, v := range shade.DataSlice() {
+		if math.Abs(v-expected) > 1e-9 {
+			t.Fatalf("平地阴影值错误: 预期 %.3f, 实际 %.3f", expected, v)
+		}
+	}
+
+	// 向东下降的坡面：西侧光照更亮，东侧光照更暗
+	slope := NewRasterDouble(5, 5, math.NaN())
+	for r := 0; r < 5; r++ {
+		for c := 0; c < 5; c++ {
+			slope.SetValue(r, c, -0.2*float64(c))
+		}
+	}
+	slope.SetValue(0, 0, math.NaN())
+	slope.SetXYPos(0, 0, 1)
+	west, _ := Hillshade(slope, 270, 45, 1)
+	east, _ := Hillshade(slope, 90, 45, 1)
+	if !(east.Value(2, 2) > expected && west.Value(2, 2) < expected) {
+		t.Errorf("坡面阴影错误: 东向光 %.2f, 西向光 %.2f", east.Value(2, 2), west.Value(2, 2))
+	}
+	steep, _ := Hillshade(slope, 90, 45, 3)
+	if steep.Value(2, 2) <= east.Value(2, 2) {
+		t.Error("z-factor 增大时迎光坡应更亮")
+	}
+	if !math.IsNaN(west.Value(0, 0)) {
+		t.Error("NoData 单元阴影应为 NaN")
+	}
+}
+
+// 南向上与非正方形像元的坡度换算
+func TestHillshadeGeoTransform(t *testing.T) {
+	// 向南下降的坡面，北向上与南向上两种存储方式
+	north := NewRasterDouble(5, 5, math.NaN())
+	south := NewRasterDouble(5, 5, math.NaN())
+	for r := 0; r < 5; r++ {
+		for c := 0; c < 5; c++ {
+			north.SetValue(r, c, -0.3*float64(r))
+			south.SetValue(r, c, -0.3*float64(4-r))
+		}
+	}
+	north.SetXYPos(0, 0, 1)
+	south.SetGeoTransform(NewGeoTransform(0, 0, 1, 1))
+	a, _ := Hillshade(north, 180, 45, 1)
+	b, _ := Hillshade(south, 180, 45, 1)
+	if math.Abs(a.Value(2, 2)-b.Value(2, 2)) > 1e-9 || a.Value(2, 2) <= 255*math.Sin(math.Pi/4) {
+		t.Errorf("南向上栅格阴影错误: %.3f %.3f", a.Value(2, 2), b.Value(2, 2))
+	}
+
+	// X 方向像元为 2 时，同样的高差对应一半坡度
+	wide := NewRasterDouble(5, 5, math.NaN())
+	gentle := NewRasterDouble(5, 5, math.NaN())
+	for r := 0; r < 5; r++ {
+		for c := 0; c < 5; c++ {
+			wide.SetValue(r, c, -0.4*float64(c))
+			gentle.SetValue(r, c, -0.2*float64(c))
+		}
+	}
+	wide.SetXYPosCellSize(0, 0, 2, 1)
+	gentle.SetXYPos(0, 0, 1)
+	a, _ = Hillshade(wide, 90, 45, 1)
+	b, _ = Hillshade(gentle, 90, 45, 1)
+	if math.Abs(a.Value(2, 2)-b.Value(2, 2)) > 1e-9 {
+		t.Errorf("非正方形像元阴影错误: %.3f %.3f", a.Value(2, 2), b.Value(2, 2))
+	}
+}
+
+func TestColorRamp(t *testing.T) {
+	ramp := ColorRamp{{0, color.RGBA{0, 0, 0, 255}}, {1, color.RGBA{200, 100, 50, 255}}}
+	if c := ramp.At(0.5); c != (color.RGBA{100, 50, 25, 255}) {
+		t.Errorf("插值颜色错误: %v", c)
+	}
+	if ramp.At(-1) != ramp[0].Color || ramp.At(2) != ramp[1].Color {
+		t.Error("超出范围时应取端点颜色")
+	}
+}
+
+func TestRenderMesh(t *testing.T) {
+	mesh := createGridMesh(4, func(x, y float64) float64 { return x + y })
+	opts := DefaultRenderOptions()
+	opts.Width = 64
+	opts.Shaded = false
+	img, err := RenderMesh(mesh, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Fatalf("图像尺寸错误: %v", b)
+	}
+	// 左下角最低，右上角最高
+	if c := img.RGBAAt(0, 63); c.B < 100 || c.R > 50 {
+		t.Errorf("最低处颜色错误: %v", c)
+	}
+	if c := img.RGBAAt(63, 0); c.R < 200 || c.G < 200 || c.B < 200 {
+		t.Errorf("最高处颜色错误: %v", c)
+	}
+
+	opts.Wireframe = true
+	opts.WireColor = color.RGBA{255, 0, 0, 255}
+	img, _ = RenderMesh(mesh, opts)
+	if c := img.RGBAAt(16, 40); c != opts.WireColor {
+		t.Errorf("线框像素颜色错误: %v", c)
+	}
+
+	filename := filepath.Join(t.TempDir(), "mesh.png")
+	if err := ExportMeshPNG(filename, mesh, nil); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := png.Decode(file); err != nil {
+		t.Errorf("PNG 解码失败: %v", err)
+	}
+}
+
+func TestRenderRaster(t *testing.T) {
+	dem := createValleyRaster()
+	img, err := RenderRaster(dem, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != dem.Cols() || b.Dy() != dem.Rows() {
+		t.Fatalf("图像尺寸错误: %v", b)
+	}
+	if c := img.RGBAAt(0, 0); c.A != 0 {
+		t.Errorf("NoData 应为背景色: %v", c)
+	}
+	if c := img.RGBAAt(4, 8); c.A != 255 {
+		t.Errorf("有效单元应不透明: %v", c)
+	}
+}
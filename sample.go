@@ -97,7 +97,6 @@ func CreateSampleDEM(bbox vec2d.Rect, zoom int, maxElev float64) *RasterDouble {
 		}
 	})
 
-	raster.Bounds = [4]float64{bbox.Min[0], bbox.Max[0], bbox.Min[1], bbox.Max[1]}
 	return raster
 }
 
@@ -203,152 +202,85 @@ type RasterAdapter struct {
 	fullCoverage geo.Coverage
 	tileGrid     *geo.TileGrid
 	originSR     geo.Proj
+
+	// 源栅格坐标系与瓦片网格不同时重投影使用的重采样方法
+	Resampling ResampleMethod
+
+	pyramid *RasterPyramid
 }
 
+// 源栅格坐标系取 coverage 的坐标系，与瓦片网格坐标系不同时按瓦片重投影
 func NewRasterAdapter(tileGrid *geo.TileGrid, coverage geo.Coverage, origin *RasterDouble) *RasterAdapter {
+	originSR := tileGrid.Srs
+	if coverage != nil && coverage.GetSrs() != nil {
+		originSR = coverage.GetSrs()
+	}
 	return &RasterAdapter{
 		origin:       origin,
 		tileGrid:     tileGrid,
 		fullCoverage: coverage,
-		originSR:     tileGrid.Srs, // 深度克隆避免外部修改
-	}
-}
-
-func intersectRect(r *vec2d.Rect, other *vec2d.Rect) *vec2d.Rect {
-	minX := math.Max(r.Min[0], other.Min[0])
-	minY := math.Max(r.Min[1], other.Min[1])
-	maxX := math.Min(r.Max[0], other.Max[0])
-	maxY := math.Min(r.Max[1], other.Max[1])
-
-	if minX >= maxX || minY >= maxY {
-		return nil
-	}
-
-	return &vec2d.Rect{
-		Min: vec2d.T{minX, minY},
-		Max: vec2d.T{maxX, maxY},
+		originSR:     originSR,
+		Resampling:   ResampleBilinear,
 	}
 }
 
+// 从源栅格 (或选中的概览) 裁剪覆盖瓦片范围的子栅格，范围吸附到源像元网格
 func (f *RasterAdapter) generator(bbox vec2d.Rect, zoom int) (*RasterDouble, error) {
 	if f.origin == nil || f.origin.Data == nil {
 		return nil, fmt.Errorf("origin raster is invalid")
 	}
+	return f.source(bbox, zoom).Crop(bbox)
+}
 
-	origin := f.origin
-	// 获取分离的XY分辨率
-	cellsize := origin.CellSize()
-	if cellsize <= 0 {
-		return nil, fmt.Errorf("invalid cellsize: %.6f", cellsize)
-	}
-
-	// 原始栅格地理范围
-	originBBox := vec2d.Rect{
-		Min: vec2d.T{origin.Bounds[0], origin.Bounds[2]},
-		Max: vec2d.T{origin.Bounds[1], origin.Bounds[3]},
-	}
+// 源栅格坐标系是否与瓦片网格不同
+func (f *RasterAdapter) reproject() bool {
+	return f.originSR != nil && f.tileGrid.Srs != nil && !f.originSR.Eq(f.tileGrid.Srs)
+}
 
-	// 计算有效交集
-	intersect := intersectRect(&bbox, &originBBox)
-	if intersect == nil {
-		return nil, fmt.Errorf("no intersection with origin raster")
+// 为源栅格生成概览，之后 GetDEM 按层级分辨率选用最接近的概览
+func (f *RasterAdapter) BuildOverviews(method OverviewMethod, minSize int) error {
+	pyramid, err := BuildPyramid(f.origin, method, minSize)
+	if err != nil {
+		return err
 	}
+	f.pyramid = pyramid
+	return nil
+}
 
-	// 计算列索引 (X方向)
-	startCol := math.Floor((intersect.Min[0] - originBBox.Min[0]) / cellsize)
-	endCol := math.Ceil((intersect.Max[0] - originBBox.Min[0]) / cellsize)
-
-	// 计算行索引 (Y方向) - 注意Y轴从顶部开始
-	startRow := math.Floor((originBBox.Max[1] - intersect.Max[1]) / math.Abs(cellsize))
-	endRow := math.Ceil((originBBox.Max[1] - intersect.Min[1]) / math.Abs(cellsize))
-
-	// 添加1个像元重叠
-	overlap := 0.0
-	startCol = math.Max(0, startCol)
-	endCol = math.Min(float64(origin.Cols()), endCol+overlap)
-	startRow = math.Max(0, startRow)
-	endRow = math.Min(float64(origin.Rows()), endRow+overlap)
-
-	// 转换为整数索引 (扩大范围确保覆盖)
-	startColInt := int(math.Floor(startCol))
-	endColInt := int(math.Ceil(endCol))
-	startRowInt := int(math.Floor(startRow))
-	endRowInt := int(math.Ceil(endRow))
-
-	// 边界检查
-	cols := origin.Cols()
-	rows := origin.Rows()
-	startColInt = max(0, startColInt)
-	endColInt = min(cols, endColInt)
-	startRowInt = max(0, startRowInt)
-	endRowInt = min(rows, endRowInt)
-
-	// 计算实际尺寸
-	subCols := endColInt - startColInt
-	subRows := endRowInt - startRowInt
-	if subCols <= 0 || subRows <= 0 {
-		return nil, fmt.Errorf("invalid subgrid size: %dx%d", subCols, subRows)
+// 选取分辨率不粗于该层级地面分辨率的最粗概览
+func (f *RasterAdapter) source(bbox vec2d.Rect, zoom int) *RasterDouble {
+	if f.pyramid == nil {
+		return f.origin
 	}
-
-	// 精确计算地理边界
-	exactMinX := originBBox.Min[0] + float64(startColInt)*cellsize
-	exactMaxX := originBBox.Min[0] + float64(endColInt)*cellsize
-	exactMaxY := originBBox.Max[1] - float64(startRowInt)*math.Abs(cellsize)
-	exactMinY := originBBox.Max[1] - float64(endRowInt)*math.Abs(cellsize)
-
-	// === 新增：边界对齐处理 ===
-	// 计算需要扩展的像元数（确保覆盖原始bbox）
-	expandCols := math.Ceil((bbox.Max[0] - exactMaxX) / cellsize)
-	expandRows := math.Ceil((exactMinY - bbox.Min[1]) / math.Abs(cellsize))
-
-	if expandCols > 0 || expandRows > 0 {
-		// 扩展列索引范围
-		endColInt = min(origin.Cols(), endColInt+int(expandCols)+1)
-		// 扩展行索引范围
-		endRowInt = min(origin.Rows(), endRowInt+int(expandRows)+1)
-
-		// 重新计算精确边界
-		exactMaxX = originBBox.Min[0] + float64(endColInt)*cellsize
-		exactMinY = originBBox.Max[1] - float64(endRowInt)*cellsize
-
-		// 更新子网格尺寸
-		subCols = endColInt - startColInt
-		subRows = endRowInt - startRowInt
-		if subCols <= 0 || subRows <= 0 {
-			return nil, fmt.Errorf("expanded subgrid size invalid: %dx%d", subCols, subRows)
-		}
+	res := f.tileGrid.Resolution(zoom)
+	if f.reproject() && bbox.Width() > 0 {
+		// 按瓦片范围在两个坐标系中的宽度比换算为源坐标系单位
+		srcBBox := f.tileGrid.Srs.TransformRectTo(f.originSR, bbox, 16)
+		res *= srcBBox.Width() / bbox.Width()
 	}
+	return f.pyramid.Select(res)
+}
 
-	// 高效数据复制
-	totalSize := subRows * subCols
-	newData := make([]float64, totalSize)
-	dataSlice := origin.DataSlice()
-
-	for r := 0; r < subRows; r++ {
-		srcRow := startRowInt + r
-		srcStart := srcRow*cols + startColInt
-		srcEnd := srcStart + subCols
-		dstStart := r * subCols
-
-		copy(newData[dstStart:dstStart+subCols], dataSlice[srcStart:srcEnd])
+// 以瓦片网格在该层级的分辨率将源栅格重投影到瓦片范围
+func (f *RasterAdapter) warp(bbox vec2d.Rect, zoom int) (*RasterDouble, error) {
+	if f.origin == nil || f.origin.Data == nil {
+		return nil, fmt.Errorf("origin raster is invalid")
 	}
-	const epsilon = 1e-3 // 用于处理浮点数精度的容差值
-
-	// 创建子栅格
-	subRaster := NewRasterDoubleWithData(subRows, subCols, newData)
-	subRaster.SetXYPos(
-		exactMinX,        // 左下角X
-		exactMinY,        // 左下角Y (修复坐标计算)
-		cellsize+epsilon, // 分离的分辨率
-	)
-	subRaster.NoData = origin.NoData
-	subRaster.Bounds = [4]float64{exactMinX, exactMaxX, exactMinY, exactMaxY}
-
-	return subRaster, nil
+	return Warp(f.source(bbox, zoom), &WarpOptions{
+		SrcSrs:   f.originSR,
+		DstSrs:   f.tileGrid.Srs,
+		Bounds:   bbox,
+		CellSize: f.tileGrid.Resolution(zoom),
+		Method:   f.Resampling,
+	})
 }
 
 func (f *RasterAdapter) GetDEM(bbox vec2d.Rect, zoom int) (*RasterDouble, error) {
-	dem, err := f.generator(bbox, zoom)
+	generate := f.generator
+	if f.reproject() {
+		generate = f.warp
+	}
+	dem, err := generate(bbox, zoom)
 	if err != nil {
 		return nil, fmt.Errorf("DEM generation failed: %w", err)
 	}
@@ -19,8 +19,9 @@ type PQ []*Candidate
 
 func (pq PQ) Len() int { return len(pq) }
 
+// 最大堆，误差最大的候选点优先出队
 func (pq PQ) Less(i, j int) bool {
-	return pq[i].Importance < pq[j].Importance
+	return pq[i].Importance > pq[j].Importance
 }
 
 func (pq PQ) Swap(i, j int) {
@@ -39,14 +40,12 @@ func (pq *PQ) Push(x interface{}) {
 }
 
 func (pq *PQ) Pop() interface{} {
-	temp := (*pq)[0]
+	old := *pq
+	l := len(old)
+	temp := old[l-1]
+	old[l-1] = nil
 	temp.index = -1
-	l := len(*pq)
-	if l > 1 {
-		*pq = (*pq)[1:]
-	} else {
-		*pq = PQ{}
-	}
+	*pq = old[:l-1]
 	return temp
 }
 
@@ -60,6 +59,7 @@ type Candidate struct {
 	Y          int
 	Z          float64
 	Importance float64
+	Error      float64 // 三角形内的最大垂直误差，与 MaxError 比较
 	Token      int
 	Triangle   *DelaunayTriangle
 	index      int
@@ -82,18 +82,20 @@ type CandidateList struct {
 	Candidates PQ
 }
 
-func (cl *CandidateList) Push(candidate *Candidate) { cl.Candidates.Push(candidate) }
+func (cl *CandidateList) Push(candidate *Candidate) { heap.Push(&cl.Candidates, candidate) }
 
 func (cl *CandidateList) Size() int { return cl.Candidates.Len() }
 
 func (cl *CandidateList) Empty() bool { return cl.Candidates.Len() == 0 }
 
+func (cl *CandidateList) Clear() { cl.Candidates = PQ{} }
+
 func (cl *CandidateList) GrabGreatest() *Candidate {
 	if cl.Empty() {
 		return &Candidate{}
 	}
 
-	candidate := cl.Candidates.Pop()
+	candidate := heap.Pop(&cl.Candidates)
 	return candidate.(*Candidate)
 }
 
@@ -137,39 +139,84 @@ type RasterMesh struct {
 	SrcProj geo.Proj            // 原始坐标系
 	Datum   geoid.VerticalDatum // 高程基准
 	Offset  float64             // 高程偏移
+
+	source ElevationRaster // LoadElevation 加载的非双精度高程栅格，Raster 为 nil 时使用
+	filled *RasterDouble   // 本次插入使用的填充后栅格，不替换加载的数据源
 }
 
 func (r *RasterMesh) LoadRaster(raster *RasterDouble) {
 	r.Raster = raster
+	r.source = nil
+	r.filled = nil
+}
+
+// 加载任意像元类型的高程栅格，窄类型 DEM 不会复制为双精度
+func (r *RasterMesh) LoadElevation(src ElevationRaster) error {
+	if src == nil || src.Grid() == nil {
+		return fmt.Errorf("nil raster")
+	}
+	if raster, ok := src.(*RasterDouble); ok {
+		r.LoadRaster(raster)
+		return nil
+	}
+	r.Raster = nil
+	r.source = src
+	r.filled = nil
+	return nil
+}
+
+// 当前高程数据源，优先使用填充后的栅格，其次为 Raster
+func (r *RasterMesh) elevation() ElevationRaster {
+	if r.filled != nil {
+		return r.filled
+	}
+	if r.Raster != nil {
+		return r.Raster
+	}
+	return r.source
+}
+
+func (r *RasterMesh) grid() *RasterGrid {
+	return r.elevation().Grid()
+}
+
+// 工作栅格使用的无效值，双精度栅格沿用其 NoData，其余为 NaN
+func (r *RasterMesh) noData() float64 {
+	if r.filled != nil {
+		return r.filled.NoData
+	}
+	if r.Raster != nil {
+		return r.Raster.NoData
+	}
+	return math.NaN()
 }
 
 func (r *RasterMesh) repairPoint(px, py float64) {
 	x, y := int(px), int(py)
-	noDataValue := r.Raster.NoData.(float64)
+	src := r.elevation()
 
 	// 检查当前点是否有效
-	currentVal := r.Raster.Value(y, x)
-	if !isNoData(currentVal, noDataValue) {
+	currentVal := src.Elevation(y, x)
+	if !math.IsNaN(currentVal) {
 		return // 点已有有效值，无需修复
 	}
 
 	// 采样最近的有效平均值
-	z := SampleNearestValidAvg(r.Raster, y, x, 3) // 搜索半径设为3
+	z := SampleNearestValidAvg(src, y, x, 3) // 搜索半径设为3
 
-	if isNoData(z, noDataValue) {
-		r.Raster.SetValue(y, x, 0.0) // 默认值
+	if math.IsNaN(z) {
+		src.SetElevation(y, x, 0.0) // 默认值
 	} else {
-		r.Raster.SetValue(y, x, z)
+		src.SetElevation(y, x, z)
 	}
 }
 
 func (r *RasterMesh) getElevation(y, x int) float64 {
-	currentVal := r.Raster.Value(y, x)
+	currentVal := r.elevation().Elevation(y, x)
 	if r.SrcProj == nil {
 		return currentVal
 	}
-	xCoord := r.Raster.ColToX(x)
-	yCoord := r.Raster.RowToY(y)
+	xCoord, yCoord := r.grid().CellCenter(y, x)
 	pt, _ := transformPoint(r.SrcProj, EPSG4326, xCoord, yCoord)
 
 	// 高程基准转换
@@ -190,21 +237,20 @@ property float x
 property float y
 property float z
 end_header
-`, r.Raster.Rows()*r.Raster.Cols())
+`, r.grid().Rows()*r.grid().Cols())
 
 	if _, err := w.Write([]byte(header)); err != nil {
 		return err
 	}
 
 	// 遍历所有栅格点
-	for y := 0; y < r.Raster.Rows(); y++ {
-		for x := 0; x < r.Raster.Cols(); x++ {
+	for y := 0; y < r.grid().Rows(); y++ {
+		for x := 0; x < r.grid().Cols(); x++ {
 			// 获取高程值（已包含坐标转换逻辑）
 			z := r.getElevation(y, x)
 
 			// 获取地理坐标
-			xCoord := r.Raster.ColToX(x)
-			yCoord := r.Raster.RowToY(y)
+			xCoord, yCoord := r.grid().CellCenter(y, x)
 
 			// 坐标转换（与getElevation保持一致）
 			pt, _ := transformPoint(r.SrcProj, EPSG3857, xCoord, yCoord)
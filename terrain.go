@@ -0,0 +1,206 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 地形导数，角度单位均为度
+// 坡向为最速下降方向，自正北 (+Y) 顺时针 0-360，平坦处为 -1
+// 曲率为拟合曲面的拉普拉斯算子 ∇²z，正值表示下凹 (谷)，负值表示上凸 (脊)
+type TerrainDerivatives struct {
+	FaceSlope       []float64
+	FaceAspect      []float64
+	FaceCurvature   []float64 // 三个顶点曲率的平均值
+	VertexSlope     []float64
+	VertexAspect    []float64
+	VertexCurvature []float64 // 一环邻域少于 5 个顶点时为 NaN
+}
+
+// 计算每个三角面与顶点的坡度、坡向和曲率
+// zFactor 为高程相对平面坐标的缩放系数，<= 0 时取 1
+// 顶点坡度坡向由相邻三角面梯度按平面面积加权平均得到
+func (m *Mesh) TerrainDerivatives(zFactor float64) *TerrainDerivatives {
+	if !m.hasDecomposed() {
+		m.GenerateDecomposed()
+	}
+	if zFactor <= 0 {
+		zFactor = 1
+	}
+
+	nf, nv := len(m.Faces), len(m.Vertices)
+	d := &TerrainDerivatives{
+		FaceSlope:       make([]float64, nf),
+		FaceAspect:      make([]float64, nf),
+		FaceCurvature:   make([]float64, nf),
+		VertexSlope:     make([]float64, nv),
+		VertexAspect:    make([]float64, nv),
+		VertexCurvature: make([]float64, nv),
+	}
+
+	grad := make([][2]float64, nv)
+	weight := make([]float64, nv)
+	neighbours := make([]map[VertexIndex]struct{}, nv)
+
+	for fi, f := range m.Faces {
+		p := [3][3]float64{m.Vertices[f[0]], m.Vertices[f[1]], m.Vertices[f[2]]}
+		for i := range p {
+			p[i][2] *= zFactor
+		}
+		area := math.Abs(Orientation([2]float64{p[0][0], p[0][1]}, [2]float64{p[1][0], p[1][1]}, [2]float64{p[2][0], p[2][1]})) / 2
+		if area == 0 {
+			d.FaceSlope[fi], d.FaceAspect[fi] = math.NaN(), math.NaN()
+			continue
+		}
+		plane := NewPlane(p[0], p[1], p[2])
+		d.FaceSlope[fi], d.FaceAspect[fi] = slopeAspect(plane[0], plane[1])
+
+		for i := range f {
+			grad[f[i]][0] += area * plane[0]
+			grad[f[i]][1] += area * plane[1]
+			weight[f[i]] += area
+			if neighbours[f[i]] == nil {
+				neighbours[f[i]] = make(map[VertexIndex]struct{})
+			}
+			neighbours[f[i]][f[(i+1)%3]] = struct{}{}
+			neighbours[f[i]][f[(i+2)%3]] = struct{}{}
+		}
+	}
+
+	for vi := range m.Vertices {
+		if weight[vi] == 0 {
+			d.VertexSlope[vi], d.VertexAspect[vi], d.VertexCurvature[vi] = math.NaN(), math.NaN(), math.NaN()
+			continue
+		}
+		d.VertexSlope[vi], d.VertexAspect[vi] = slopeAspect(grad[vi][0]/weight[vi], grad[vi][1]/weight[vi])
+		d.VertexCurvature[vi] = m.vertexCurvature(VertexIndex(vi), neighbours[vi], zFactor)
+	}
+
+	for fi, f := range m.Faces {
+		d.FaceCurvature[fi] = (d.VertexCurvature[f[0]] + d.VertexCurvature[f[1]] + d.VertexCurvature[f[2]]) / 3
+	}
+	return d
+}
+
+// 由梯度 (dz/dx, dz/dy) 求坡度与坡向
+func slopeAspect(dx, dy float64) (slope, aspect float64) {
+	g := math.Hypot(dx, dy)
+	slope = math.Atan(g) * 180 / math.Pi
+	if g < EPS {
+		return slope, -1
+	}
+	aspect = math.Atan2(-dx, -dy) * 180 / math.Pi
+	if aspect < 0 {
+		aspect += 360
+	}
+	return slope, aspect
+}
+
+// 以顶点为原点对一环邻域最小二乘拟合 z = a x² + b y² + c xy + d x + e y，返回 2a + 2b
+func (m *Mesh) vertexCurvature(vi VertexIndex, ring map[VertexIndex]struct{}, zFactor float64) float64 {
+	if len(ring) < 5 {
+		return math.NaN()
+	}
+	o := m.Vertices[vi]
+	var ata [5][5]float64
+	var atb [5]float64
+	for ni := range ring {
+		v := m.Vertices[ni]
+		x, y, z := v[0]-o[0], v[1]-o[1], (v[2]-o[2])*zFactor
+		row := [5]float64{x * x, y * y, x * y, x, y}
+		for i := range row {
+			for j := range row {
+				ata[i][j] += row[i] * row[j]
+			}
+			atb[i] += row[i] * z
+		}
+	}
+	coef, ok := solveLinear5(ata, atb)
+	if !ok {
+		return math.NaN()
+	}
+	return 2*coef[0] + 2*coef[1]
+}
+
+// 列主元高斯消元求解 5 元线性方程组
+func solveLinear5(a [5][5]float64, b [5]float64) ([5]float64, bool) {
+	var x [5]float64
+	scale := 0.0
+	for i := range a {
+		for j := range a[i] {
+			scale = math.Max(scale, math.Abs(a[i][j]))
+		}
+	}
+	if scale == 0 {
+		return x, false
+	}
+	for c := 0; c < 5; c++ {
+		p := c
+		for r := c + 1; r < 5; r++ {
+			if math.Abs(a[r][c]) > math.Abs(a[p][c]) {
+				p = r
+			}
+		}
+		if math.Abs(a[p][c]) < scale*1e-12 {
+			return x, false
+		}
+		a[c], a[p] = a[p], a[c]
+		b[c], b[p] = b[p], b[c]
+		for r := c + 1; r < 5; r++ {
+			k := a[r][c] / a[c][c]
+			for j := c; j < 5; j++ {
+				a[r][j] -= k * a[c][j]
+			}
+			b[r] -= k * b[c]
+		}
+	}
+	for r := 4; r >= 0; r-- {
+		s := b[r]
+		for j := r + 1; j < 5; j++ {
+			s -= a[r][j] * x[j]
+		}
+		x[r] = s / a[r][r]
+	}
+	return x, true
+}
+
+// 坡度 (度) 转换为百分比坡度
+func SlopePercent(deg float64) float64 {
+	return math.Tan(deg*math.Pi/180) * 100
+}
+
+// 按升序分级断点对坡度分级，breaks[i-1] <= s < breaks[i] 的值属于第 i 级
+// 结果取值 0..len(breaks)，NaN 为 -1
+func ClassifySlope(slopes []float64, breaks []float64) ([]int, error) {
+	if !sort.Float64sAreSorted(breaks) {
+		return nil, fmt.Errorf("slope class breaks must be ascending")
+	}
+	classes := make([]int, len(slopes))
+	for i, s := range slopes {
+		if math.IsNaN(s) {
+			classes[i] = -1
+			continue
+		}
+		classes[i] = sort.Search(len(breaks), func(k int) bool { return breaks[k] > s })
+	}
+	return classes, nil
+}
+
+// 导出为三角面属性，供 Mesh.ExportPLY 使用
+// classes 非空时追加 slope_class 属性
+func (d *TerrainDerivatives) FaceAttributes(classes []int) []FaceAttribute {
+	attrs := []FaceAttribute{
+		{Name: "slope", Values: d.FaceSlope},
+		{Name: "aspect", Values: d.FaceAspect},
+		{Name: "curvature", Values: d.FaceCurvature},
+	}
+	if len(classes) > 0 {
+		values := make([]float64, len(classes))
+		for i, c := range classes {
+			values[i] = float64(c)
+		}
+		attrs = append(attrs, FaceAttribute{Name: "slope_class", Values: values})
+	}
+	return attrs
+}
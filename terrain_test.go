@@ -0,0 +1,89 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTerrainDerivatives(t *testing.T) {
+	t.Run("Plane", func(t *testing.T) {
+		cases := []struct {
+			h      func(x, y float64) float64
+			slope  float64
+			aspect float64
+		}{
+			{func(x, y float64) float64 { return x }, 45, 270},
+			{func(x, y float64) float64 { return -x }, 45, 90},
+			{func(x, y float64) float64 { return 2 * y }, math.Atan(2) * 180 / math.Pi, 180},
+			{func(x, y float64) float64 { return 5 }, 0, -1},
+		}
+		for _, c := range cases {
+			d := createGridMesh(3, c.h).TerrainDerivatives(1)
+			for fi := range d.FaceSlope {
+				if math.Abs(d.FaceSlope[fi]-c.slope) > 1e-9 || math.Abs(d.FaceAspect[fi]-c.aspect) > 1e-9 {
+					t.Errorf("三角面 %d 错误: 预期 (%.3f, %.3f), 实际 (%.3f, %.3f)",
+						fi, c.slope, c.aspect, d.FaceSlope[fi], d.FaceAspect[fi])
+				}
+			}
+			for vi := range d.VertexSlope {
+				if math.Abs(d.VertexSlope[vi]-c.slope) > 1e-9 || math.Abs(d.VertexAspect[vi]-c.aspect) > 1e-9 {
+					t.Errorf("顶点 %d 错误: 预期 (%.3f, %.3f), 实际 (%.3f, %.3f)",
+						vi, c.slope, c.aspect, d.VertexSlope[vi], d.VertexAspect[vi])
+				}
+			}
+		}
+	})
+
+	t.Run("ZFactor", func(t *testing.T) {
+		d := createGridMesh(2, func(x, y float64) float64 { return x }).TerrainDerivatives(2)
+		expected := math.Atan(2) * 180 / math.Pi
+		if math.Abs(d.FaceSlope[0]-expected) > 1e-9 {
+			t.Errorf("坡度错误: 预期 %.4f, 实际 %.4f", expected, d.FaceSlope[0])
+		}
+	})
+
+	t.Run("Curvature", func(t *testing.T) {
+		bowl := createGridMesh(4, func(x, y float64) float64 { return (x-2)*(x-2) + (y-2)*(y-2) })
+		d := bowl.TerrainDerivatives(1)
+		interior := 0
+		for vi, v := range bowl.Vertices {
+			if v[0] <= 0 || v[0] >= 4 || v[1] <= 0 || v[1] >= 4 {
+				continue
+			}
+			interior++
+			if math.Abs(d.VertexCurvature[vi]-4) > 1e-9 {
+				t.Errorf("顶点 %v 曲率错误: 预期 4, 实际 %.6f", v, d.VertexCurvature[vi])
+			}
+		}
+		if interior != 9 {
+			t.Fatalf("内部顶点数错误: %d", interior)
+		}
+
+		ridge := createGridMesh(4, func(x, y float64) float64 { return -(x - 2) * (x - 2) })
+		d = ridge.TerrainDerivatives(1)
+		for vi, v := range ridge.Vertices {
+			if v[0] == 2 && v[1] == 2 && math.Abs(d.VertexCurvature[vi]+2) > 1e-9 {
+				t.Errorf("脊线曲率错误: 预期 -2, 实际 %.6f", d.VertexCurvature[vi])
+			}
+		}
+	})
+}
+
+func TestClassifySlope(t *testing.T) {
+	classes, err := ClassifySlope([]float64{0, 4.9, 5, 14, 15, 60, math.NaN()}, []float64{5, 15, 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{0, 0, 1, 1, 2, 3, -1}
+	for i := range expected {
+		if classes[i] != expected[i] {
+			t.Errorf("分级 %d 错误: 预期 %d, 实际 %d", i, expected[i], classes[i])
+		}
+	}
+	if _, err := ClassifySlope([]float64{1}, []float64{10, 5}); err == nil {
+		t.Error("断点未升序应返回错误")
+	}
+	if p := SlopePercent(45); math.Abs(p-100) > 1e-9 {
+		t.Errorf("百分比坡度错误: %.4f", p)
+	}
+}
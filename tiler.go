@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
 	"sync/atomic"
 
@@ -50,6 +51,8 @@ type TinTilerConfig struct {
 	SpecificZooms []int
 	Concurrency   int
 	MaxError      float64
+	MaxVertices   int         // 单个瓦片的顶点预算，0 表示不限制
+	MaxTriangles  int         // 单个瓦片的三角形预算，0 表示不限制
 	Provider      DemProvider // 替换原来的DEMLoader
 	Exporter      TileExporter
 	Progress      Progress
@@ -57,6 +60,7 @@ type TinTilerConfig struct {
 	AutoZoom      bool
 	Datum         geoid.VerticalDatum
 	Offset        float64
+	VoidFill      *VoidFillOptions // 生成 TIN 前填充 DEM 中的 NoData，nil 表示不填充
 }
 
 type TinTiler struct {
@@ -212,6 +216,14 @@ func (t *TinTiler) startWorkers(ctx context.Context) {
 	}
 }
 
+// 瓦片协程之间平分 CPU，避免每个瓦片再各自按核数起协程
+func (t *TinTiler) meshWorkers() int {
+	if n := runtime.GOMAXPROCS(0) / t.config.Concurrency; n > 1 {
+		return n
+	}
+	return 1
+}
+
 func (t *TinTiler) worker(ctx context.Context) {
 	for {
 		select {
@@ -304,13 +316,28 @@ func (t *TinTiler) processTile(task *tileTask) {
 		dem.Cols(), dem.Rows(), dem.CellSize(), dem.Bounds,
 	))
 
+	if t.config.VoidFill != nil {
+		if dem, err = FillVoids(dem, t.config.VoidFill); err != nil {
+			t.reportError(fmt.Errorf("tile %d/%d/%d DEM填充失败: %w", task.zoom, task.x, task.y, err))
+			return
+		}
+	}
+
 	// 生成TIN
 	t.config.Progress.Log("Generating TIN mesh...")
-	_, mesh := GenerateTinMesh(dem, t.config.MaxError, &GeoConfig{
+	zemlya := NewZemlyaMesh(&GeoConfig{
 		SrcProj: t.config.TileGrid.Srs,
 		Datum:   t.config.Datum,
 		Offset:  t.config.Offset,
 	})
+	zemlya.Workers = t.meshWorkers()
+	zemlya.LoadRaster(dem)
+	zemlya.GreedyInsertWithBudget(t.config.MaxError, t.config.MaxVertices, t.config.MaxTriangles)
+	mesh := zemlya.ToMesh()
+	t.config.Progress.Log(fmt.Sprintf(
+		"TIN generated: vertices=%d triangles=%d maxError=%.3f",
+		len(mesh.Vertices), len(mesh.Faces), zemlya.AchievedError,
+	))
 
 	// 导出瓦片
 	relPath := t.config.Exporter.RelativeTilePath(task.zoom, task.x, task.y)
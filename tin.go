@@ -7,6 +7,24 @@ func GenerateTinMesh(raster *RasterDouble, maxError float64, config *GeoConfig)
 	return g, g.ToMesh()
 }
 
+// 在顶点/三角形预算下生成TIN，maxError 与预算先达到者终止插入
+func GenerateTinMeshWithBudget(raster *RasterDouble, maxError float64, maxVertices, maxTriangles int, config *GeoConfig) (*ZemlyaMesh, *Mesh) {
+	g := NewZemlyaMesh(config)
+	g.LoadRaster(raster)
+	g.GreedyInsertWithBudget(maxError, maxVertices, maxTriangles)
+	return g, g.ToMesh()
+}
+
+// 由任意像元类型的高程栅格生成TIN，如 NewScaledRaster 包装的 int16 DEM
+func GenerateTinMeshFromElevation(src ElevationRaster, maxError float64, maxVertices, maxTriangles int, config *GeoConfig) (*ZemlyaMesh, *Mesh, error) {
+	g := NewZemlyaMesh(config)
+	if err := g.LoadElevation(src); err != nil {
+		return nil, nil, err
+	}
+	g.GreedyInsertWithBudget(maxError, maxVertices, maxTriangles)
+	return g, g.ToMesh(), nil
+}
+
 type TileMaker struct {
 	mesh *Mesh
 }
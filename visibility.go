@@ -0,0 +1,235 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+)
+
+// 地球平均半径 (米)
+const EarthRadius = 6371008.8
+
+// 通视分析选项，平面坐标与高程须为同一长度单位 (米)
+type LineOfSightOptions struct {
+	ObserverHeight float64 // 观察点离地 (或离起点 z) 高度
+	TargetHeight   float64 // 目标点高度
+	EarthCurvature bool    // 是否进行地球曲率改正
+	Refraction     float64 // 大气折射系数，常用 0.13，仅在 EarthCurvature 为 true 时生效
+}
+
+// 距观察点 d 处由地球曲率与大气折射引起的地面下降量
+func (o *LineOfSightOptions) drop(d float64) float64 {
+	if !o.EarthCurvature {
+		return 0
+	}
+	return (1 - o.Refraction) * d * d / (2 * EarthRadius)
+}
+
+type LineOfSightResult struct {
+	Visible             bool
+	Obstruction         Vertex  // 首个遮挡点的地面坐标，仅在不可见时有效
+	ObstructionDistance float64 // 首个遮挡点距观察点的平面距离
+}
+
+// 依次检查视线路径上的地面采样点，observer/target 已包含高度偏移
+func lineOfSight(observer, target Vertex, samples []ProfileSample, opts *LineOfSightOptions) *LineOfSightResult {
+	length := math.Hypot(target[0]-observer[0], target[1]-observer[1])
+	eye := observer[2]
+	end := target[2] - opts.drop(length)
+
+	for _, s := range samples {
+		if math.IsNaN(s.Z) || s.Distance <= EPS || s.Distance >= length-EPS {
+			continue
+		}
+		sight := eye + (end-eye)*s.Distance/length
+		if s.Z-opts.drop(s.Distance) > sight+EPS {
+			return &LineOfSightResult{
+				Visible:             false,
+				Obstruction:         Vertex{s.X, s.Y, s.Z},
+				ObstructionDistance: s.Distance,
+			}
+		}
+	}
+	return &LineOfSightResult{Visible: true, ObstructionDistance: -1}
+}
+
+// 判断两个三维点之间在 Mesh 上是否通视，高度偏移分别加在两点的 z 上
+// 视线沿途在每条三角形边的交点处检查地形遮挡
+func MeshLineOfSight(mesh *Mesh, observer, target Vertex, opts *LineOfSightOptions) (*LineOfSightResult, error) {
+	return NewMeshIndex(mesh).LineOfSight(observer, target, opts)
+}
+
+func (idx *MeshIndex) LineOfSight(observer, target Vertex, opts *LineOfSightOptions) (*LineOfSightResult, error) {
+	if opts == nil {
+		opts = &LineOfSightOptions{}
+	}
+	observer[2] += opts.ObserverHeight
+	target[2] += opts.TargetHeight
+
+	profile, err := idx.Profile([][2]float64{{observer[0], observer[1]}, {target[0], target[1]}}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return lineOfSight(observer, target, profile.Samples, opts), nil
+}
+
+// 判断两个三维点之间在栅格 DEM 上是否通视，沿视线每半个像元双线性插值采样
+func RasterLineOfSight(raster *RasterDouble, observer, target Vertex, opts *LineOfSightOptions) (*LineOfSightResult, error) {
+	if raster == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	if raster.minCellSize() <= 0 {
+		return nil, fmt.Errorf("invalid cellsize: %.6f", raster.CellSize())
+	}
+	if opts == nil {
+		opts = &LineOfSightOptions{}
+	}
+	observer[2] += opts.ObserverHeight
+	target[2] += opts.TargetHeight
+	return lineOfSight(observer, target, rasterSightSamples(nil, raster, observer, target), opts), nil
+}
+
+// 采样追加到 samples 后返回，可传入复用的缓冲区
+func rasterSightSamples(samples []ProfileSample, raster *RasterDouble, observer, target Vertex) []ProfileSample {
+	length := math.Hypot(target[0]-observer[0], target[1]-observer[1])
+	n := int(math.Ceil(length / (raster.minCellSize() / 2)))
+	for i := 1; i < n; i++ {
+		t := float64(i) / float64(n)
+		x := observer[0] + t*(target[0]-observer[0])
+		y := observer[1] + t*(target[1]-observer[1])
+		col, row := raster.geoToPixel(x, y)
+		samples = append(samples, ProfileSample{Distance: t * length, X: x, Y: y, Z: SampleBilinear(raster, row, col)})
+	}
+	return samples
+}
+
+// 可视域栅格取值
+const (
+	ViewshedInvisible int8 = 0
+	ViewshedVisible   int8 = 1
+	ViewshedNoData    int8 = -1
+)
+
+// 计算观察点 (x, y) 在 DEM 上半径 radius 内的可视域
+// 观察点高程取 DEM 地面高程加 ObserverHeight，各单元以地面高程加 TargetHeight 作为目标
+// 返回与 DEM 同地理参考的栅格，半径外及 DEM 无效单元为 ViewshedNoData
+// 采用径向扫描：沿每条视线累计地形最大仰角，单元按经过它的视线判定，任一视线可见即可见，
+// 结果与逐单元 RasterLineOfSight 在少量临界单元上可能不同
+func Viewshed(raster *RasterDouble, x, y, radius float64, opts *LineOfSightOptions) (*RasterChar, error) {
+	if raster == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	if raster.minCellSize() <= 0 {
+		return nil, fmt.Errorf("invalid cellsize: %.6f", raster.CellSize())
+	}
+	if radius <= 0 {
+		return nil, fmt.Errorf("invalid radius: %.6f", radius)
+	}
+	if opts == nil {
+		opts = &LineOfSightOptions{}
+	}
+
+	col, row := raster.geoToPixel(x, y)
+	ground := SampleBilinear(raster, row, col)
+	if math.IsNaN(ground) {
+		return nil, fmt.Errorf("observer (%.3f, %.3f) is outside the raster or on NoData", x, y)
+	}
+	observer := Vertex{x, y, ground + opts.ObserverHeight}
+
+	rows, cols := raster.Rows(), raster.Cols()
+	noDataValue := raster.NoData
+	result := NewRasterChar(rows, cols, ViewshedNoData)
+	result.copyGeoReference(&raster.RasterGrid)
+
+	cellRadius := radius / raster.minCellSize()
+	r0 := max(0, int(math.Floor(row-cellRadius)))
+	r1 := MinInt(rows-1, int(math.Ceil(row+cellRadius)))
+	c0 := max(0, int(math.Floor(col-cellRadius)))
+	c1 := MinInt(cols-1, int(math.Ceil(col+cellRadius)))
+	eye := observer[2]
+
+	// 单元中心在半径内且有效时返回其距离
+	cellDistance := func(r, c int) (float64, bool) {
+		tx, ty := raster.pixelToGeo(float64(c), float64(r))
+		d := math.Hypot(tx-x, ty-y)
+		return d, d <= radius && !isNoData(raster.Value(r, c), noDataValue)
+	}
+
+	// 以视线已经过地形的最大仰角判定单元，任一视线可见即为可见
+	visit := func(r, c int, maxSlope float64) {
+		if result.Value(r, c) == ViewshedVisible {
+			return
+		}
+		d, ok := cellDistance(r, c)
+		if !ok {
+			return
+		}
+		z := raster.Value(r, c) + opts.TargetHeight - opts.drop(d)
+		if d <= EPS || (z-eye)/d >= maxSlope-EPS {
+			result.SetValue(r, c, ViewshedVisible)
+		} else {
+			result.SetValue(r, c, ViewshedInvisible)
+		}
+	}
+
+	// 从观察点射向 (er, ec) 单元中心，每半个像元采样一次并累计地形最大仰角
+	reach := radius + raster.maxCellSize()
+	sweep := func(er, ec int) {
+		dr, dc := float64(er)-row, float64(ec)-col
+		n := int(math.Ceil(2 * math.Max(math.Abs(dr), math.Abs(dc))))
+		ex, ey := raster.pixelToGeo(float64(ec), float64(er))
+		length := math.Hypot(ex-x, ey-y)
+		maxSlope := math.Inf(-1)
+		lastR, lastC := -1, -1
+		for i := 1; i <= n; i++ {
+			t := float64(i) / float64(n)
+			pr, pc := row+t*dr, col+t*dc
+			r, c := int(math.Round(pr)), int(math.Round(pc))
+			if (r != lastR || c != lastC) && r >= 0 && r < rows && c >= 0 && c < cols {
+				visit(r, c, maxSlope)
+				lastR, lastC = r, c
+			}
+			d := t * length
+			if d > reach {
+				break
+			}
+			if z := SampleBilinear(raster, pr, pc); !math.IsNaN(z) && d > EPS {
+				maxSlope = math.Max(maxSlope, (z-opts.drop(d)-eye)/d)
+			}
+		}
+	}
+
+	// 视线射向窗口边界上的每个单元，窗口内每个单元至少被一条视线经过，总代价 O(R²)
+	for c := c0; c <= c1; c++ {
+		sweep(r0, c)
+		sweep(r1, c)
+	}
+	for r := r0 + 1; r < r1; r++ {
+		sweep(r, c0)
+		sweep(r, c1)
+	}
+	if r, c := int(math.Round(row)), int(math.Round(col)); r >= 0 && r < rows && c >= 0 && c < cols {
+		visit(r, c, math.Inf(-1))
+	}
+
+	// 个别未被视线经过的单元逐个判定，复用同一采样缓冲区
+	var samples []ProfileSample
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			if result.Value(r, c) != ViewshedNoData {
+				continue
+			}
+			if _, ok := cellDistance(r, c); !ok {
+				continue
+			}
+			tx, ty := raster.pixelToGeo(float64(c), float64(r))
+			target := Vertex{tx, ty, raster.Value(r, c) + opts.TargetHeight}
+			samples = rasterSightSamples(samples[:0], raster, observer, target)
+			if lineOfSight(observer, target, samples, opts).Visible {
+				result.SetValue(r, c, ViewshedVisible)
+			} else {
+				result.SetValue(r, c, ViewshedInvisible)
+			}
+		}
+	}
+	return result, nil
+}
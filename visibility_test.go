@@ -0,0 +1,170 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeshLineOfSight(t *testing.T) {
+	ridge := createGridMesh(4, func(x, y float64) float64 {
+		if x == 2 {
+			return 5
+		}
+		return 0
+	})
+	idx := NewMeshIndex(ridge)
+
+	r, err := idx.LineOfSight(Vertex{0.5, 2, 0}, Vertex{3.5, 2, 0}, &LineOfSightOptions{ObserverHeight: 1, TargetHeight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Visible {
+		t.Fatal("山脊后的目标应不可见")
+	}
+	if !r.Obstruction.Equal(Vertex{2, 2, 5}) || math.Abs(r.ObstructionDistance-1.5) > 1e-9 {
+		t.Errorf("遮挡点错误: %v, %.3f", r.Obstruction, r.ObstructionDistance)
+	}
+
+	r, _ = idx.LineOfSight(Vertex{0.5, 2, 0}, Vertex{3.5, 2, 0}, &LineOfSightOptions{ObserverHeight: 10, TargetHeight: 10})
+	if !r.Visible {
+		t.Error("视线高于山脊时应可见")
+	}
+
+	t.Run("EarthCurvature", func(t *testing.T) {
+		flat := createScaledGridMesh(4, 20000, func(x, y float64) float64 { return 0 })
+		from, to := Vertex{0, 10000, 0}, Vertex{20000, 10000, 0}
+		opts := &LineOfSightOptions{ObserverHeight: 2, TargetHeight: 2}
+		if r, _ := MeshLineOfSight(flat, from, to, opts); !r.Visible {
+			t.Error("不考虑地球曲率时平地应可见")
+		}
+		opts.EarthCurvature = true
+		opts.Refraction = 0.13
+		if r, _ := MeshLineOfSight(flat, from, to, opts); r.Visible {
+			t.Error("考虑地球曲率时 20km 外的低矮目标应不可见")
+		}
+	})
+}
+
+// 21x21 平地，第 12 列为高 10 的墙
+func createWallRaster() *RasterDouble {
+	raster := NewRasterDouble(21, 21, math.NaN())
+	for r := 0; r < 21; r++ {
+		for c := 0; c < 21; c++ {
+			z := 0.0
+			if c == 12 {
+				z = 10
+			}
+			raster.SetValue(r, c, z)
+		}
+	}
+	raster.SetXYPos(0, 0, 1)
+	return raster
+}
+
+func TestRasterLineOfSight(t *testing.T) {
+	raster := createWallRaster()
+	// 第 10 行第 5 列与第 18 列的单元中心
+	from, to := Vertex{5.5, 10.5, 0}, Vertex{18.5, 10.5, 0}
+
+	r, err := RasterLineOfSight(raster, from, to, &LineOfSightOptions{ObserverHeight: 2, TargetHeight: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Visible {
+		t.Fatal("墙后的目标应不可见")
+	}
+	if r.Obstruction[0] < 11 || r.Obstruction[0] > 13 {
+		t.Errorf("遮挡点应在墙附近: %v", r.Obstruction)
+	}
+
+	r, _ = RasterLineOfSight(raster, from, to, &LineOfSightOptions{ObserverHeight: 40, TargetHeight: 2})
+	if !r.Visible {
+		t.Error("足够高的观察点应可见")
+	}
+}
+
+func TestViewshed(t *testing.T) {
+	raster := createWallRaster()
+	raster.SetValue(10, 8, math.NaN())
+
+	vs, err := Viewshed(raster, 5.5, 10.5, 9, &LineOfSightOptions{ObserverHeight: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		row, col int
+		expected int8
+	}{
+		{10, 5, ViewshedVisible},
+		{10, 11, ViewshedVisible},
+		{10, 12, ViewshedVisible}, // 墙顶
+		{10, 13, ViewshedInvisible},
+		{12, 11, ViewshedVisible},
+		{12, 13, ViewshedInvisible},
+		{10, 8, ViewshedNoData},  // DEM 无效值
+		{10, 15, ViewshedNoData}, // 半径外
+		{0, 0, ViewshedNoData},
+	}
+	for _, c := range cases {
+		if v := vs.Value(c.row, c.col); v != c.expected {
+			t.Errorf("单元 (%d, %d) 错误: 预期 %d, 实际 %d", c.row, c.col, c.expected, v)
+		}
+	}
+	if vs.CellSize() != raster.CellSize() || vs.Bounds != raster.Bounds {
+		t.Error("可视域栅格应与 DEM 具有相同的地理参考")
+	}
+
+	if _, err := Viewshed(raster, 50, 50, 8, nil); err == nil {
+		t.Error("栅格外的观察点应返回错误")
+	}
+}
+
+// 径向扫描结果应与逐单元视线分析基本一致
+func TestViewshedMatchesLineOfSight(t *testing.T) {
+	const size = 81
+	raster := NewRasterDouble(size, size, math.NaN())
+	for r := 0; r < size; r++ {
+		for c := 0; c < size; c++ {
+			raster.SetValue(r, c, 6*math.Sin(float64(c)/5)+4*math.Cos(float64(r)/7)+0.05*float64(r*c%13))
+		}
+	}
+	raster.SetXYPosCellSize(0, 0, 2, 3)
+
+	opts := &LineOfSightOptions{ObserverHeight: 1.5, TargetHeight: 0.5}
+	x, y, radius := 81.0, 121.0, 110.0
+	vs, err := Viewshed(raster, x, y, radius, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	col, row := raster.geoToPixel(x, y)
+	observer := Vertex{x, y, SampleBilinear(raster, row, col)}
+
+	total, differ := 0, 0
+	for r := 0; r < size; r++ {
+		for c := 0; c < size; c++ {
+			tx, ty := raster.pixelToGeo(float64(c), float64(r))
+			if math.Hypot(tx-x, ty-y) > radius {
+				if vs.Value(r, c) != ViewshedNoData {
+					t.Fatalf("半径外单元 (%d, %d) 应为 NoData", r, c)
+				}
+				continue
+			}
+			res, err := RasterLineOfSight(raster, observer, Vertex{tx, ty, raster.Value(r, c)}, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := ViewshedInvisible
+			if res.Visible {
+				want = ViewshedVisible
+			}
+			total++
+			if vs.Value(r, c) != want {
+				differ++
+			}
+		}
+	}
+	t.Logf("不一致单元 %d/%d", differ, total)
+	if float64(differ) > 0.03*float64(total) {
+		t.Errorf("径向扫描与逐单元视线分析差异过大: %d/%d", differ, total)
+	}
+}
@@ -0,0 +1,320 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+)
+
+// 无效值填充方法
+type VoidFillMethod int
+
+const (
+	VoidFillNone             VoidFillMethod = iota
+	VoidFillIDW                             // 反距离加权
+	VoidFillNaturalNeighbour                // 基于空洞边缘单元 Delaunay 三角网的自然邻域插值
+	VoidFillLaplacian                       // 拉普拉斯 (薄膜) 插值，填充值为调和函数
+)
+
+type VoidFillOptions struct {
+	Method        VoidFillMethod
+	SearchRadius  int     // IDW 搜索半径 (像元)，默认 16
+	Power         float64 // IDW 距离幂次，默认 2
+	MaxIterations int     // 拉普拉斯迭代次数上限，默认 1000
+	Tolerance     float64 // 拉普拉斯收敛阈值，默认 1e-4
+}
+
+func (o *VoidFillOptions) validate() error {
+	switch o.Method {
+	case VoidFillNone, VoidFillIDW, VoidFillNaturalNeighbour, VoidFillLaplacian:
+		return nil
+	}
+	return fmt.Errorf("unknown void fill method: %d", o.Method)
+}
+
+func (o *VoidFillOptions) normalize() VoidFillOptions {
+	opts := *o
+	if opts.SearchRadius <= 0 {
+		opts.SearchRadius = 16
+	}
+	if opts.Power <= 0 {
+		opts.Power = 2
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 1000
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 1e-4
+	}
+	return opts
+}
+
+// 填充 NoData 单元，返回新栅格，有效单元保持不变
+// 无法插值的单元 (如搜索半径内没有有效值，或空洞不与任何有效单元相邻) 仍为 NoData
+func FillVoids(raster *RasterDouble, opts *VoidFillOptions) (*RasterDouble, error) {
+	if raster == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	if opts == nil {
+		opts = &VoidFillOptions{Method: VoidFillIDW}
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	o := opts.normalize()
+
+	filled := raster.Clone()
+	switch o.Method {
+	case VoidFillNone:
+	case VoidFillIDW:
+		fillVoidsIDW(raster, filled, o.SearchRadius, o.Power)
+	case VoidFillNaturalNeighbour:
+		fillVoidsNaturalNeighbour(raster, filled)
+	case VoidFillLaplacian:
+		fillVoidsLaplacian(raster, filled, o.MaxIterations, o.Tolerance)
+	}
+	return filled, nil
+}
+
+func fillVoidsIDW(src, dst *RasterDouble, radius int, power float64) {
+	rows, cols := src.Rows(), src.Cols()
+	noDataValue := src.NoData
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if !isNoData(src.Value(r, c), noDataValue) {
+				continue
+			}
+			sum, weight := 0.0, 0.0
+			for dr := -radius; dr <= radius; dr++ {
+				for dc := -radius; dc <= radius; dc++ {
+					nr, nc := r+dr, c+dc
+					if nr < 0 || nr >= rows || nc < 0 || nc >= cols {
+						continue
+					}
+					d := math.Hypot(float64(dr), float64(dc))
+					if d > float64(radius) {
+						continue
+					}
+					v := src.Value(nr, nc)
+					if isNoData(v, noDataValue) {
+						continue
+					}
+					w := 1 / math.Pow(d, power)
+					sum += w * v
+					weight += w
+				}
+			}
+			if weight > 0 {
+				dst.SetValue(r, c, sum/weight)
+			}
+		}
+	}
+}
+
+// 与 NoData 单元八邻接的有效单元
+func voidEdgeCells(src *RasterDouble) [][2]int {
+	rows, cols := src.Rows(), src.Cols()
+	noDataValue := src.NoData
+	var cells [][2]int
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if isNoData(src.Value(r, c), noDataValue) {
+				continue
+			}
+			edge := false
+			for _, o := range d8Offsets {
+				nr, nc := r+o.dr, c+o.dc
+				if nr >= 0 && nr < rows && nc >= 0 && nc < cols && isNoData(src.Value(nr, nc), noDataValue) {
+					edge = true
+					break
+				}
+			}
+			if edge {
+				cells = append(cells, [2]int{r, c})
+			}
+		}
+	}
+	return cells
+}
+
+// 自然邻域插值 (Laplace 权重，即非 Sibson 形式)：以空洞边缘单元构建 Delaunay 三角网，
+// 待插值点的自然邻点为外接圆包含该点的三角形顶点，权重为插入该点后
+// 与各邻点共享的 Voronoi 边长度除以到邻点的距离
+func fillVoidsNaturalNeighbour(src, dst *RasterDouble) {
+	rows, cols := src.Rows(), src.Cols()
+	noDataValue := src.NoData
+	edges := voidEdgeCells(src)
+	if len(edges) == 0 {
+		return
+	}
+
+	// 像素空间 (列, 行) 三角网，范围向外扩展一个像元，角点不参与加权
+	mesh := &DelaunayMesh{
+		QuadEdges:    NewEdgeArena(),
+		Triangles:    NewArena[DelaunayTriangle](),
+		scanTriangle: func(*DelaunayTriangle) {},
+	}
+	mesh.InitMeshFromBBox(BBox2d{-1, -1, float64(cols), float64(rows)})
+	for _, e := range edges {
+		mesh.Insert([2]float64{float64(e[1]), float64(e[0])}, nil)
+	}
+
+	value := func(p [2]float64) (float64, bool) {
+		c, r := int(p[0]), int(p[1])
+		if p[0] != float64(c) || p[1] != float64(r) || r < 0 || r >= rows || c < 0 || c >= cols {
+			return 0, false
+		}
+		v := src.Value(r, c)
+		return v, !isNoData(v, noDataValue)
+	}
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if !isNoData(src.Value(r, c), noDataValue) {
+				continue
+			}
+			q := [2]float64{float64(c), float64(r)}
+			ring := mesh.naturalNeighbours(q)
+			sum, weight := 0.0, 0.0
+			n := len(ring)
+			for i, p := range ring {
+				v, ok := value(p)
+				if !ok {
+					continue
+				}
+				c1 := Circumcenter(q, ring[(i+n-1)%n], p)
+				c2 := Circumcenter(q, p, ring[(i+1)%n])
+				l := math.Hypot(c1[0]-c2[0], c1[1]-c2[1])
+				d := math.Hypot(p[0]-q[0], p[1]-q[1])
+				if math.IsNaN(l) || d == 0 {
+					continue
+				}
+				sum += v * l / d
+				weight += l / d
+			}
+			if weight > 0 {
+				dst.SetValue(r, c, sum/weight)
+			}
+		}
+	}
+}
+
+// Bowyer-Watson 空腔：外接圆包含 q 的全部三角形，返回其边界上按环绕顺序排列的顶点
+func (m *DelaunayMesh) naturalNeighbours(q [2]float64) [][2]float64 {
+	e := m.locate(q, m.startingQuadEdge)
+	start := e.LeftFace()
+	if start == nil {
+		return nil
+	}
+
+	inCavity := map[*DelaunayTriangle]bool{start: true}
+	stack := []*DelaunayTriangle{start}
+	next := make(map[[2]float64][2]float64)
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, edge := range [3]*QuadEdge{t.Anchor, t.Anchor.LeftNext(), t.Anchor.LeftPrev()} {
+			nb := edge.Sym().LeftFace()
+			if nb != nil && !inCavity[nb] {
+				p := nb.Points()
+				if InCircumcircle(p[0], p[1], p[2], q) {
+					inCavity[nb] = true
+					stack = append(stack, nb)
+					continue
+				}
+			}
+			if nb == nil || !inCavity[nb] {
+				next[edge.Orig()] = edge.Dest()
+			}
+		}
+	}
+
+	ring := make([][2]float64, 0, len(next))
+	for first := range next {
+		for p := first; len(ring) < len(next); {
+			ring = append(ring, p)
+			if p = next[p]; p == first {
+				break
+			}
+		}
+		break
+	}
+	if len(ring) != len(next) {
+		return nil
+	}
+	return ring
+}
+
+// 拉普拉斯填充：空洞单元迭代取四邻域均值 (逐次超松弛)，有效单元作为边界条件
+// 以各空洞边界的有效值均值为初值
+func fillVoidsLaplacian(src, dst *RasterDouble, maxIterations int, tolerance float64) {
+	rows, cols := src.Rows(), src.Cols()
+	noDataValue := src.NoData
+	out := dst.DataSlice()
+	void := func(i int) bool {
+		return isNoData(src.Value(i/cols, i%cols), noDataValue)
+	}
+	neighbours4 := func(i int, fn func(n int)) {
+		r, c := i/cols, i%cols
+		if r > 0 {
+			fn(i - cols)
+		}
+		if r < rows-1 {
+			fn(i + cols)
+		}
+		if c > 0 {
+			fn(i - 1)
+		}
+		if c < cols-1 {
+			fn(i + 1)
+		}
+	}
+
+	// 按四连通区域找出空洞，只填充与有效单元相邻的空洞
+	var cells []int
+	visited := make([]bool, rows*cols)
+	for start := range visited {
+		if visited[start] || !void(start) {
+			continue
+		}
+		visited[start] = true
+		component := []int{start}
+		sum, count := 0.0, 0
+		for k := 0; k < len(component); k++ {
+			neighbours4(component[k], func(n int) {
+				if !void(n) {
+					sum += out[n]
+					count++
+				} else if !visited[n] {
+					visited[n] = true
+					component = append(component, n)
+				}
+			})
+		}
+		if count == 0 {
+			continue
+		}
+		for _, i := range component {
+			out[i] = sum / float64(count)
+		}
+		cells = append(cells, component...)
+	}
+
+	const omega = 1.5
+	for iter := 0; iter < maxIterations; iter++ {
+		maxChange := 0.0
+		for _, i := range cells {
+			sum, count := 0.0, 0
+			neighbours4(i, func(n int) {
+				sum += out[n]
+				count++
+			})
+			change := omega * (sum/float64(count) - out[i])
+			out[i] += change
+			maxChange = math.Max(maxChange, math.Abs(change))
+		}
+		if maxChange < tolerance {
+			break
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+// 20x20 平面 z = 2c + 3r + 1，中部有一块 NoData 空洞，边角另有一个单元空洞
+func createHoleRaster() *RasterDouble {
+	raster := NewRasterDouble(20, 20, math.NaN())
+	for r := 0; r < 20; r++ {
+		for c := 0; c < 20; c++ {
+			if r >= 6 && r <= 11 && c >= 5 && c <= 12 {
+				continue
+			}
+			raster.SetValue(r, c, planeValue(r, c))
+		}
+	}
+	raster.SetValue(0, 19, math.NaN())
+	raster.SetXYPos(0, 0, 1)
+	return raster
+}
+
+func planeValue(r, c int) float64 {
+	return 2*float64(c) + 3*float64(r) + 1
+}
+
+func TestFillVoids(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    VoidFillMethod
+		tolerance float64
+	}{
+		{"IDW", VoidFillIDW, 15},
+		{"NaturalNeighbour", VoidFillNaturalNeighbour, 1e-6},
+		{"Laplacian", VoidFillLaplacian, 1e-3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := createHoleRaster()
+			filled, err := FillVoids(src, &VoidFillOptions{Method: tt.method, Tolerance: 1e-8, MaxIterations: 5000})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !math.IsNaN(src.Value(8, 8)) {
+				t.Error("不应修改输入栅格")
+			}
+			for r := 0; r < 20; r++ {
+				for c := 0; c < 20; c++ {
+					v := filled.Value(r, c)
+					if math.IsNaN(v) {
+						t.Fatalf("(%d, %d) 未被填充", r, c)
+					}
+					if !math.IsNaN(src.Value(r, c)) && v != src.Value(r, c) {
+						t.Fatalf("有效单元 (%d, %d) 被修改: %.4f -> %.4f", r, c, src.Value(r, c), v)
+					}
+					// 角点空洞只有两个有效邻域，不要求精确还原平面
+					if r == 0 && c == 19 {
+						continue
+					}
+					if d := math.Abs(v - planeValue(r, c)); d > tt.tolerance {
+						t.Errorf("(%d, %d) = %.6f, 期望约 %.6f", r, c, v, planeValue(r, c))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFillVoidsUnfillable(t *testing.T) {
+	raster := NewRasterDouble(5, 5, math.NaN())
+	for _, method := range []VoidFillMethod{VoidFillIDW, VoidFillNaturalNeighbour, VoidFillLaplacian} {
+		filled, err := FillVoids(raster, &VoidFillOptions{Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range filled.DataSlice() {
+			if !math.IsNaN(v) {
+				t.Fatalf("方法 %d: 全为 NoData 的栅格不应被填充", method)
+			}
+		}
+	}
+
+	// 搜索半径内没有有效值的单元保持 NoData
+	raster = NewRasterDouble(1, 40, math.NaN())
+	raster.SetValue(0, 0, 1)
+	filled, _ := FillVoids(raster, &VoidFillOptions{Method: VoidFillIDW, SearchRadius: 4})
+	if filled.Value(0, 4) != 1 || !math.IsNaN(filled.Value(0, 5)) {
+		t.Errorf("IDW 搜索半径无效: %.4f, %.4f", filled.Value(0, 4), filled.Value(0, 5))
+	}
+
+	if _, err := FillVoids(raster, &VoidFillOptions{Method: VoidFillMethod(99)}); err == nil {
+		t.Error("未知方法应返回错误")
+	}
+}
+
+func TestZemlyaMeshVoidFill(t *testing.T) {
+	raster := createWaveRaster()
+	for r := 10; r < 18; r++ {
+		for c := 12; c < 20; c++ {
+			raster.SetValue(r, c, math.NaN())
+		}
+	}
+
+	z := NewZemlyaMesh(&GeoConfig{})
+	z.LoadRaster(raster)
+	z.VoidFill = &VoidFillOptions{Method: VoidFillLaplacian}
+	z.GreedyInsert(0.5)
+
+	if !math.IsNaN(raster.Value(12, 14)) {
+		t.Error("不应修改输入栅格")
+	}
+	if z.Raster != raster {
+		t.Error("填充结果不应替换加载的栅格")
+	}
+	for _, v := range z.filled.DataSlice() {
+		if math.IsNaN(v) {
+			t.Fatal("GreedyInsert 应使用填充后的栅格")
+		}
+	}
+	for _, v := range z.ToMesh().Vertices {
+		if math.IsNaN(v[2]) {
+			t.Fatalf("网格顶点 %v 高程无效", v)
+		}
+	}
+	if z.AchievedError > 0.5+EPS {
+		t.Errorf("实际误差 %.6f 超过阈值", z.AchievedError)
+	}
+}
+
+func TestZemlyaMeshVoidFillSource(t *testing.T) {
+	wave := createWaveRaster()
+	short := ConvertRaster(wave, 100, 0, int16(-9999))
+	for r := 10; r < 18; r++ {
+		for c := 12; c < 20; c++ {
+			short.SetValue(r, c, -9999)
+		}
+	}
+	src := NewScaledRaster(short, 0.01, 0)
+
+	z := NewZemlyaMesh(&GeoConfig{})
+	if err := z.LoadElevation(src); err != nil {
+		t.Fatal(err)
+	}
+	z.VoidFill = &VoidFillOptions{Method: VoidFillIDW}
+	z.GreedyInsert(0.5)
+	first := len(z.ToMesh().Vertices)
+	if z.VoidFillErr != nil {
+		t.Fatal(z.VoidFillErr)
+	}
+
+	// 重复插入仍以加载的 int16 栅格为数据源
+	z.GreedyInsert(0.5)
+	if z.Raster != nil || z.source != src {
+		t.Fatal("填充结果不应替换加载的数据源")
+	}
+	if n := len(z.ToMesh().Vertices); n != first {
+		t.Errorf("重复插入顶点数 %d, 期望 %d", n, first)
+	}
+
+	z.VoidFill = &VoidFillOptions{Method: VoidFillMethod(99)}
+	z.GreedyInsert(0.5)
+	if z.VoidFillErr == nil {
+		t.Error("未知填充方法应记录错误")
+	}
+	if z.filled != nil {
+		t.Error("填充失败时应使用未填充的栅格")
+	}
+}
@@ -0,0 +1,281 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+)
+
+// 土方计算选项
+type VolumeOptions struct {
+	Boundary          [][2]float64 // 可选的边界多边形 (可为凹多边形)，为空时统计两个曲面的全部重叠区域
+	DifferenceSurface bool         // 是否输出差值曲面 (existing - design)
+}
+
+// 土方计算结果，面积为平面投影面积
+// 挖方为现状高于设计的部分，填方为设计高于现状的部分，NetVolume = CutVolume - FillVolume
+type VolumeReport struct {
+	CutVolume  float64
+	FillVolume float64
+	NetVolume  float64
+	CutArea    float64
+	FillArea   float64
+	TotalArea  float64 // 参与计算的总面积，包含高差为 0 的区域
+	Difference *Mesh
+}
+
+func (r *VolumeReport) String() string {
+	return fmt.Sprintf("cut=%.3f fill=%.3f net=%.3f cutArea=%.3f fillArea=%.3f area=%.3f",
+		r.CutVolume, r.FillVolume, r.NetVolume, r.CutArea, r.FillArea, r.TotalArea)
+}
+
+// 计算现状曲面与设计曲面之间的挖填方量
+// 两个曲面须处于同一坐标系，只统计两者平面投影重叠的区域
+func CutFillVolume(existing, design *Mesh, opts *VolumeOptions) (*VolumeReport, error) {
+	if existing == nil || design == nil {
+		return nil, fmt.Errorf("nil mesh")
+	}
+	idx := NewMeshIndex(design)
+	return cutFillVolume(existing, opts, func(poly [][2]float64, emit func([][2]float64, *Plane)) {
+		b := BBox2d{math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64}
+		for _, p := range poly {
+			b.add(p[:])
+		}
+		for _, fi := range idx.facesInBBox(b) {
+			f := design.Faces[fi]
+			v := [3][3]float64{design.Vertices[f[0]], design.Vertices[f[1]], design.Vertices[f[2]]}
+			tri := [3][2]float64{{v[0][0], v[0][1]}, {v[1][0], v[1][1]}, {v[2][0], v[2][1]}}
+			if Orientation(tri[0], tri[1], tri[2]) == 0 {
+				continue
+			}
+			if piece := clipPolygonByTriangle(poly, tri); len(piece) >= 3 {
+				emit(piece, NewPlane(v[0], v[1], v[2]))
+			}
+		}
+	})
+}
+
+// 计算现状曲面与高程为 z 的水平参考面之间的挖填方量
+func CutFillVolumeToPlane(existing *Mesh, z float64, opts *VolumeOptions) (*VolumeReport, error) {
+	if existing == nil {
+		return nil, fmt.Errorf("nil mesh")
+	}
+	plane := &Plane{0, 0, z}
+	return cutFillVolume(existing, opts, func(poly [][2]float64, emit func([][2]float64, *Plane)) {
+		emit(poly, plane)
+	})
+}
+
+// 遍历现状曲面的三角形，由 overlay 将其与设计曲面叠加切分为凸多边形，
+// 每个凸多边形内两曲面均为平面，高差为线性函数，可精确积分
+func cutFillVolume(existing *Mesh, opts *VolumeOptions, overlay func(poly [][2]float64, emit func([][2]float64, *Plane))) (*VolumeReport, error) {
+	if opts == nil {
+		opts = &VolumeOptions{}
+	}
+
+	var boundary [][3][2]float64
+	if len(opts.Boundary) > 0 {
+		var err error
+		if boundary, err = triangulatePolygon(opts.Boundary); err != nil {
+			return nil, err
+		}
+	}
+
+	if !existing.hasDecomposed() {
+		existing.GenerateDecomposed()
+	}
+
+	report := &VolumeReport{}
+	var diff []Triangle
+
+	accumulate := func(poly [][2]float64, top, bottom *Plane) {
+		d := func(p [2]float64) float64 {
+			return top.Eval(p[0], p[1]) - bottom.Eval(p[0], p[1])
+		}
+		report.TotalArea += polygonArea(poly)
+
+		minD, maxD := math.Inf(1), math.Inf(-1)
+		for _, p := range poly {
+			minD = math.Min(minD, d(p))
+			maxD = math.Max(maxD, d(p))
+		}
+		// 高差为线性函数，按零线切分挖方区与填方区
+		if cut := clipPolygon(poly, d); maxD > 0 && len(cut) >= 3 {
+			a := polygonArea(cut)
+			report.CutArea += a
+			report.CutVolume += a * d(polygonCentroid(cut))
+		}
+		neg := func(p [2]float64) float64 { return -d(p) }
+		if fill := clipPolygon(poly, neg); minD < 0 && len(fill) >= 3 {
+			a := polygonArea(fill)
+			report.FillArea += a
+			report.FillVolume += a * neg(polygonCentroid(fill))
+		}
+
+		if opts.DifferenceSurface {
+			for i := 1; i+1 < len(poly); i++ {
+				diff = append(diff, Triangle{
+					{poly[0][0], poly[0][1], d(poly[0])},
+					{poly[i][0], poly[i][1], d(poly[i])},
+					{poly[i+1][0], poly[i+1][1], d(poly[i+1])},
+				})
+			}
+		}
+	}
+
+	for _, f := range existing.Faces {
+		v := [3][3]float64{existing.Vertices[f[0]], existing.Vertices[f[1]], existing.Vertices[f[2]]}
+		tri := [3][2]float64{{v[0][0], v[0][1]}, {v[1][0], v[1][1]}, {v[2][0], v[2][1]}}
+		if Orientation(tri[0], tri[1], tri[2]) == 0 {
+			continue
+		}
+		top := NewPlane(v[0], v[1], v[2])
+
+		pieces := [][][2]float64{tri[:]}
+		if boundary != nil {
+			pieces = pieces[:0]
+			for _, bt := range boundary {
+				if p := clipPolygonByTriangle(tri[:], bt); len(p) >= 3 {
+					pieces = append(pieces, p)
+				}
+			}
+		}
+		for _, p := range pieces {
+			overlay(p, func(poly [][2]float64, bottom *Plane) {
+				accumulate(poly, top, bottom)
+			})
+		}
+	}
+
+	report.NetVolume = report.CutVolume - report.FillVolume
+	if opts.DifferenceSurface {
+		report.Difference = NewMesh(existing.GeoRef)
+		report.Difference.InitFromTriangles(diff)
+	}
+	return report, nil
+}
+
+// Sutherland-Hodgman 裁剪，保留 f(p) >= 0 的部分，f 须为线性函数
+func clipPolygon(poly [][2]float64, f func(p [2]float64) float64) [][2]float64 {
+	if len(poly) == 0 {
+		return nil
+	}
+	out := make([][2]float64, 0, len(poly)+1)
+	prev := poly[len(poly)-1]
+	fp := f(prev)
+	for _, cur := range poly {
+		fc := f(cur)
+		if (fp >= 0) != (fc >= 0) {
+			t := fp / (fp - fc)
+			out = append(out, [2]float64{prev[0] + t*(cur[0]-prev[0]), prev[1] + t*(cur[1]-prev[1])})
+		}
+		if fc >= 0 {
+			out = append(out, cur)
+		}
+		prev, fp = cur, fc
+	}
+	return out
+}
+
+// 用三角形裁剪凸多边形，三角形顶点顺序任意
+func clipPolygonByTriangle(poly [][2]float64, tri [3][2]float64) [][2]float64 {
+	if Orientation(tri[0], tri[1], tri[2]) < 0 {
+		tri[1], tri[2] = tri[2], tri[1]
+	}
+	for i := 0; i < 3 && len(poly) >= 3; i++ {
+		a, b := tri[i], tri[(i+1)%3]
+		poly = clipPolygon(poly, func(p [2]float64) float64 {
+			return Orientation(a, b, p)
+		})
+	}
+	return poly
+}
+
+func polygonSignedArea(poly [][2]float64) float64 {
+	s := 0.0
+	for i := range poly {
+		p, q := poly[i], poly[(i+1)%len(poly)]
+		s += p[0]*q[1] - q[0]*p[1]
+	}
+	return s / 2
+}
+
+func polygonArea(poly [][2]float64) float64 {
+	return math.Abs(polygonSignedArea(poly))
+}
+
+func polygonCentroid(poly [][2]float64) [2]float64 {
+	var cx, cy, a float64
+	for i := range poly {
+		p, q := poly[i], poly[(i+1)%len(poly)]
+		w := p[0]*q[1] - q[0]*p[1]
+		a += w
+		cx += (p[0] + q[0]) * w
+		cy += (p[1] + q[1]) * w
+	}
+	if a == 0 {
+		for _, p := range poly {
+			cx += p[0]
+			cy += p[1]
+		}
+		return [2]float64{cx / float64(len(poly)), cy / float64(len(poly))}
+	}
+	return [2]float64{cx / (3 * a), cy / (3 * a)}
+}
+
+// 耳切法三角化简单多边形，首尾重复点会被忽略
+func triangulatePolygon(poly [][2]float64) ([][3][2]float64, error) {
+	pts := append([][2]float64(nil), poly...)
+	if len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+		pts = pts[:len(pts)-1]
+	}
+	if len(pts) < 3 {
+		return nil, fmt.Errorf("polygon needs at least 3 points, got %d", len(pts))
+	}
+	if polygonSignedArea(pts) < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+
+	var tris [][3][2]float64
+	for len(pts) > 3 {
+		n := len(pts)
+		found := false
+		for i := 0; i < n; i++ {
+			a, b, c := pts[(i+n-1)%n], pts[i], pts[(i+1)%n]
+			o := Orientation(a, b, c)
+			if o < 0 {
+				continue
+			}
+			if o == 0 {
+				// 共线点直接移除
+				pts = append(pts[:i], pts[i+1:]...)
+				found = true
+				break
+			}
+			ear := true
+			for j := 0; j < n && ear; j++ {
+				if j == i || j == (i+n-1)%n || j == (i+1)%n {
+					continue
+				}
+				p := pts[j]
+				if Orientation(a, b, p) >= 0 && Orientation(b, c, p) >= 0 && Orientation(c, a, p) >= 0 {
+					ear = false
+				}
+			}
+			if ear {
+				tris = append(tris, [3][2]float64{a, b, c})
+				pts = append(pts[:i], pts[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("polygon is not simple")
+		}
+	}
+	if Orientation(pts[0], pts[1], pts[2]) > 0 {
+		tris = append(tris, [3][2]float64{pts[0], pts[1], pts[2]})
+	}
+	return tris, nil
+}
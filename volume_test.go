@@ -0,0 +1,107 @@
+package tin
+
+import (
+	"math"
+	"testing"
+)
+
+// 将 createGridMesh 生成的网格缩放到 [0, size] 范围
+func createScaledGridMesh(n int, size float64, h func(x, y float64) float64) *Mesh {
+	s := size / float64(n)
+	mesh := createGridMesh(n, func(x, y float64) float64 { return h(x*s, y*s) })
+	for i := range mesh.Triangles {
+		for j := range mesh.Triangles[i] {
+			mesh.Triangles[i][j][0] *= s
+			mesh.Triangles[i][j][1] *= s
+		}
+	}
+	return mesh
+}
+
+func TestCutFillVolume(t *testing.T) {
+	check := func(t *testing.T, r *VolumeReport, cut, fill, cutArea, fillArea, area float64) {
+		t.Helper()
+		got := []float64{r.CutVolume, r.FillVolume, r.NetVolume, r.CutArea, r.FillArea, r.TotalArea}
+		want := []float64{cut, fill, cut - fill, cutArea, fillArea, area}
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 1e-9 {
+				t.Errorf("结果错误: 预期 cut=%.3f fill=%.3f cutArea=%.3f fillArea=%.3f area=%.3f, 实际 %s",
+					cut, fill, cutArea, fillArea, area, r)
+				return
+			}
+		}
+	}
+	tilted := func(x, y float64) float64 { return x - 2 }
+
+	t.Run("ConstantPlane", func(t *testing.T) {
+		r, err := CutFillVolumeToPlane(createGridMesh(4, tilted), 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, r, 8, 8, 8, 8, 16)
+
+		r, _ = CutFillVolumeToPlane(createGridMesh(4, tilted), -2, nil)
+		check(t, r, 32, 0, 16, 0, 16)
+	})
+
+	t.Run("TwoMeshes", func(t *testing.T) {
+		// 两个曲面的三角网互不对齐
+		existing := createScaledGridMesh(3, 4, tilted)
+		design := createScaledGridMesh(5, 4, func(x, y float64) float64 { return 0 })
+		r, err := CutFillVolume(existing, design, &VolumeOptions{DifferenceSurface: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, r, 8, 8, 8, 8, 16)
+
+		// 差值曲面的体积与净方量一致
+		net := 0.0
+		for _, tri := range r.Difference.Triangles {
+			a := polygonArea([][2]float64{{tri[0][0], tri[0][1]}, {tri[1][0], tri[1][1]}, {tri[2][0], tri[2][1]}})
+			net += a * (tri[0][2] + tri[1][2] + tri[2][2]) / 3
+		}
+		if math.Abs(net-r.NetVolume) > 1e-9 {
+			t.Errorf("差值曲面体积错误: %.6f", net)
+		}
+
+		// 相同曲面没有挖填方
+		r, _ = CutFillVolume(existing, createScaledGridMesh(7, 4, tilted), nil)
+		if r.CutVolume > 1e-9 || r.FillVolume > 1e-9 || r.CutArea > 1e-9 || r.FillArea > 1e-9 {
+			t.Errorf("相同曲面结果错误: %s", r)
+		}
+	})
+
+	t.Run("PartialOverlap", func(t *testing.T) {
+		existing := createGridMesh(4, func(x, y float64) float64 { return 1 })
+		design := createScaledGridMesh(2, 2, func(x, y float64) float64 { return 0 })
+		r, err := CutFillVolume(existing, design, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, r, 4, 0, 4, 0, 4)
+	})
+
+	t.Run("Boundary", func(t *testing.T) {
+		mesh := createGridMesh(4, tilted)
+		r, err := CutFillVolumeToPlane(mesh, 0, &VolumeOptions{
+			Boundary: [][2]float64{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, r, 1, 1, 2, 2, 4)
+
+		// L 形凹多边形，顺时针
+		r, err = CutFillVolumeToPlane(mesh, 0, &VolumeOptions{
+			Boundary: [][2]float64{{0, 0}, {0, 4}, {2, 4}, {2, 2}, {4, 2}, {4, 0}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, r, 4, 8, 4, 8, 12)
+
+		if _, err := CutFillVolumeToPlane(mesh, 0, &VolumeOptions{Boundary: [][2]float64{{0, 0}, {1, 1}}}); err == nil {
+			t.Error("无效边界应返回错误")
+		}
+	})
+}
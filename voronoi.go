@@ -0,0 +1,132 @@
+package tin
+
+import (
+	"math"
+)
+
+// 遍历三角剖分中的全部三角形
+func (m *DelaunayMesh) ForEachTriangle(fn func(t *DelaunayTriangle)) {
+	for t := m.firstFace; t != nil; t = t.GetLink() {
+		fn(t)
+	}
+}
+
+// 遍历三角剖分中的全部边，每条无向边只访问一次
+func (m *DelaunayMesh) ForEachEdge(fn func(e *QuadEdge)) {
+	seen := make(map[*QuadEdge]struct{})
+	m.ForEachTriangle(func(t *DelaunayTriangle) {
+		for _, e := range [3]*QuadEdge{t.Anchor, t.Anchor.LeftNext(), t.Anchor.LeftPrev()} {
+			if _, ok := seen[e]; ok {
+				continue
+			}
+			seen[e] = struct{}{}
+			seen[e.Sym()] = struct{}{}
+			fn(e)
+		}
+	})
+}
+
+// 三角形的三个顶点
+func (t *DelaunayTriangle) Points() [3][2]float64 {
+	return [3][2]float64{t.point1(), t.point2(), t.point3()}
+}
+
+// 三角形的外接圆圆心，即对偶 Voronoi 图的顶点
+func (t *DelaunayTriangle) Circumcenter() [2]float64 {
+	return Circumcenter(t.point1(), t.point2(), t.point3())
+}
+
+// 边 e 左侧的三角形，位于外部区域时返回 nil
+func (m *DelaunayMesh) validLeftFace(e *QuadEdge) *DelaunayTriangle {
+	t := e.LeftFace()
+	if t == nil || t.Anchor == nil {
+		return nil
+	}
+	if t.Anchor != e && t.Anchor.LeftNext() != e && t.Anchor.LeftPrev() != e {
+		return nil
+	}
+	return t
+}
+
+// Voronoi 单元，Polygon 为逆时针顶点序列 (首尾不重复)
+type VoronoiCell struct {
+	Site    [2]float64
+	Polygon [][2]float64
+}
+
+func (c *VoronoiCell) Area() float64 {
+	return polygonArea(c.Polygon)
+}
+
+// 提取每个顶点的 Voronoi 单元，并裁剪到顶点包围盒内
+// 内部顶点由相邻三角形的外接圆圆心按环绕顺序构成，
+// 凸包上的顶点单元无界，由与各相邻顶点的垂直平分半平面求交得到
+func (m *DelaunayMesh) VoronoiCells() []VoronoiCell {
+	// 每个顶点记录一条出边
+	spokes := make(map[[2]float64]*QuadEdge)
+	var sites [][2]float64
+	bbox := BBox2d{math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64}
+	m.ForEachTriangle(func(t *DelaunayTriangle) {
+		for _, e := range [3]*QuadEdge{t.Anchor, t.Anchor.LeftNext(), t.Anchor.LeftPrev()} {
+			o := e.Orig()
+			if _, ok := spokes[o]; !ok {
+				spokes[o] = e
+				sites = append(sites, o)
+				bbox.add(o[:])
+			}
+		}
+	})
+
+	rect := [][2]float64{{bbox[0], bbox[1]}, {bbox[2], bbox[1]}, {bbox[2], bbox[3]}, {bbox[0], bbox[3]}}
+	cells := make([]VoronoiCell, 0, len(sites))
+	for _, site := range sites {
+		start := spokes[site]
+
+		var ring [][2]float64
+		var neighbours [][2]float64
+		bounded := true
+		e := start
+		for {
+			neighbours = append(neighbours, e.Dest())
+			if t := m.validLeftFace(e); t != nil {
+				ring = append(ring, t.Circumcenter())
+			} else {
+				bounded = false
+			}
+			e = e.OrigNext()
+			if e == start || e == nil {
+				break
+			}
+		}
+
+		var poly [][2]float64
+		if bounded {
+			if polygonSignedArea(ring) < 0 {
+				for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+					ring[i], ring[j] = ring[j], ring[i]
+				}
+			}
+			poly = clipPolygonByRect(ring, bbox)
+		} else {
+			poly = append([][2]float64(nil), rect...)
+			for _, n := range neighbours {
+				// 保留距 site 不远于 n 的一侧
+				mid := [2]float64{(site[0] + n[0]) / 2, (site[1] + n[1]) / 2}
+				dir := [2]float64{n[0] - site[0], n[1] - site[1]}
+				poly = clipPolygon(poly, func(p [2]float64) float64 {
+					return -((p[0]-mid[0])*dir[0] + (p[1]-mid[1])*dir[1])
+				})
+			}
+		}
+		cells = append(cells, VoronoiCell{Site: site, Polygon: poly})
+	}
+	return cells
+}
+
+// 用轴对齐矩形裁剪凸多边形
+func clipPolygonByRect(poly [][2]float64, b BBox2d) [][2]float64 {
+	poly = clipPolygon(poly, func(p [2]float64) float64 { return p[0] - b[0] })
+	poly = clipPolygon(poly, func(p [2]float64) float64 { return b[2] - p[0] })
+	poly = clipPolygon(poly, func(p [2]float64) float64 { return p[1] - b[1] })
+	return clipPolygon(poly, func(p [2]float64) float64 { return b[3] - p[1] })
+}
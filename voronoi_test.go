@@ -0,0 +1,96 @@
+package tin
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func createTestDelaunayMesh(points [][2]float64) *DelaunayMesh {
+	mesh := &DelaunayMesh{
+		QuadEdges:    NewEdgeArena(),
+		Triangles:    NewArena[DelaunayTriangle](),
+		scanTriangle: func(*DelaunayTriangle) {},
+	}
+	mesh.InitMeshFromBBox(BBox2d{0, 0, 10, 10})
+	for _, p := range points {
+		mesh.Insert(p, nil)
+	}
+	return mesh
+}
+
+func TestDelaunayIterators(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	var points [][2]float64
+	for i := 0; i < 20; i++ {
+		points = append(points, [2]float64{0.5 + 9*rnd.Float64(), 0.5 + 9*rnd.Float64()})
+	}
+	mesh := createTestDelaunayMesh(points)
+
+	// 4 个角点均在凸包上: T = 2V - 2 - h, E = 3V - 3 - h
+	k := len(points)
+	var tris []*DelaunayTriangle
+	mesh.ForEachTriangle(func(tri *DelaunayTriangle) { tris = append(tris, tri) })
+	if len(tris) != 2*k+2 {
+		t.Errorf("三角形数错误: 预期 %d, 实际 %d", 2*k+2, len(tris))
+	}
+	edges := 0
+	mesh.ForEachEdge(func(e *QuadEdge) { edges++ })
+	if edges != 3*k+5 {
+		t.Errorf("边数错误: 预期 %d, 实际 %d", 3*k+5, edges)
+	}
+
+	sites := append([][2]float64{{0, 0}, {10, 0}, {0, 10}, {10, 10}}, points...)
+	for _, tri := range tris {
+		p := tri.Points()
+		for _, s := range sites {
+			if isEqual(s, p[0]) || isEqual(s, p[1]) || isEqual(s, p[2]) {
+				continue
+			}
+			if InCircumcircle(p[0], p[1], p[2], s) {
+				t.Errorf("点 %v 位于三角形 %v 的外接圆内", s, p)
+			}
+		}
+	}
+
+	t.Run("VoronoiCells", func(t *testing.T) {
+		cells := mesh.VoronoiCells()
+		if len(cells) != len(sites) {
+			t.Fatalf("单元数错误: 预期 %d, 实际 %d", len(sites), len(cells))
+		}
+		total := 0.0
+		for _, c := range cells {
+			total += c.Area()
+			if polygonSignedArea(c.Polygon) <= 0 {
+				t.Errorf("单元 %v 应为逆时针", c.Site)
+			}
+		}
+		if math.Abs(total-100) > 1e-6 {
+			t.Errorf("单元面积之和应等于包围盒面积 100, 实际 %.6f", total)
+		}
+
+		// 随机点所在的单元即最近顶点的单元
+		inside := func(poly [][2]float64, p [2]float64) bool {
+			for i := range poly {
+				if Orientation(poly[i], poly[(i+1)%len(poly)], p) < -1e-9 {
+					return false
+				}
+			}
+			return true
+		}
+		for i := 0; i < 200; i++ {
+			p := [2]float64{10 * rnd.Float64(), 10 * rnd.Float64()}
+			nearest, best := [2]float64{}, math.Inf(1)
+			for _, s := range sites {
+				if d := math.Hypot(p[0]-s[0], p[1]-s[1]); d < best {
+					nearest, best = s, d
+				}
+			}
+			for _, c := range cells {
+				if c.Site == nearest && !inside(c.Polygon, p) {
+					t.Errorf("点 %v 应位于 %v 的单元内", p, nearest)
+				}
+			}
+		}
+	})
+}
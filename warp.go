@@ -0,0 +1,219 @@
+package tin
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+
+	"github.com/flywave/go-geo"
+	vec2d "github.com/flywave/go3d/float64/vec2"
+)
+
+// 重采样方法
+type ResampleMethod int
+
+const (
+	ResampleNearest  ResampleMethod = iota // 最邻近
+	ResampleBilinear                       // 双线性
+	ResampleCubic                          // 双三次 (Catmull-Rom)
+	ResampleAverage                        // 目标像元覆盖范围内有效源像元的均值，适合降采样
+)
+
+type WarpOptions struct {
+	SrcSrs    geo.Proj       // 源栅格坐标系，为 nil 或与 DstSrs 相同时不做投影变换
+	DstSrs    geo.Proj       // 目标坐标系
+	Bounds    vec2d.Rect     // 目标范围 (目标坐标系)，为空时取源栅格范围变换后的外包矩形
+	CellSize  float64        // 目标分辨率，<= 0 时按源栅格像元数推算
+	CellSizeY float64        // Y 方向目标分辨率，<= 0 时与 CellSize 相同
+	Method    ResampleMethod // 重采样方法
+	Workers   int            // 并行协程数，0 表示 GOMAXPROCS，1 表示顺序执行
+}
+
+// 最邻近采样，像素坐标整数处为像元中心，超出栅格或为 NoData 时返回 NaN
+func SampleNearest(src *RasterDouble, row, col float64) float64 {
+	r := int(math.Floor(row + 0.5))
+	c := int(math.Floor(col + 0.5))
+	if r < 0 || c < 0 || r >= src.Rows() || c >= src.Cols() {
+		return math.NaN()
+	}
+	v := src.Value(r, c)
+	if isNoData(v, src.NoData) {
+		return math.NaN()
+	}
+	return v
+}
+
+// Catmull-Rom 三次卷积核
+func cubicWeight(t float64) float64 {
+	t = math.Abs(t)
+	switch {
+	case t < 1:
+		return 1.5*t*t*t - 2.5*t*t + 1
+	case t < 2:
+		return -0.5*t*t*t + 2.5*t*t - 4*t + 2
+	}
+	return 0
+}
+
+// 双三次插值，4x4 邻域中有 NoData 或越界时退化为双线性插值
+func SampleCubic(src *RasterDouble, row, col float64) float64 {
+	h, w := src.Rows(), src.Cols()
+	r0 := int(math.Floor(row))
+	c0 := int(math.Floor(col))
+	if r0 < 1 || c0 < 1 || r0+2 >= h || c0+2 >= w {
+		return SampleBilinear(src, row, col)
+	}
+	noDataValue := src.NoData
+
+	sum := 0.0
+	for dr := -1; dr <= 2; dr++ {
+		wr := cubicWeight(row - float64(r0+dr))
+		for dc := -1; dc <= 2; dc++ {
+			v := src.Value(r0+dr, c0+dc)
+			if isNoData(v, noDataValue) {
+				return SampleBilinear(src, row, col)
+			}
+			sum += v * wr * cubicWeight(col-float64(c0+dc))
+		}
+	}
+	return sum
+}
+
+// 像素坐标范围 [row0, row1] x [col0, col1] 内中心落入的有效像元均值
+// 范围内没有像元中心 (升采样) 时退化为最邻近采样
+func sampleAverage(src *RasterDouble, row0, col0, row1, col1 float64) float64 {
+	rs := max(0, int(math.Ceil(row0)))
+	re := MinInt(src.Rows()-1, int(math.Floor(row1)))
+	cs := max(0, int(math.Ceil(col0)))
+	ce := MinInt(src.Cols()-1, int(math.Floor(col1)))
+	if rs > re || cs > ce {
+		return SampleNearest(src, (row0+row1)/2, (col0+col1)/2)
+	}
+	noDataValue := src.NoData
+
+	sum, count := 0.0, 0
+	for r := rs; r <= re; r++ {
+		for c := cs; c <= ce; c++ {
+			if v := src.Value(r, c); !isNoData(v, noDataValue) {
+				sum += v
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return math.NaN()
+	}
+	return sum / float64(count)
+}
+
+// 将栅格重投影/重采样到目标坐标系、范围与分辨率，返回以 NaN 为 NoData 的新栅格
+// 源栅格 NoData 不参与插值，目标像元落在源栅格外或无有效源像元时为 NaN
+func Warp(src *RasterDouble, opts *WarpOptions) (*RasterDouble, error) {
+	if src == nil {
+		return nil, fmt.Errorf("nil raster")
+	}
+	if src.minCellSize() <= 0 {
+		return nil, fmt.Errorf("invalid cellsize: %.6f", src.minCellSize())
+	}
+	if opts == nil {
+		opts = &WarpOptions{Method: ResampleBilinear}
+	}
+
+	reproject := opts.SrcSrs != nil && opts.DstSrs != nil && !opts.SrcSrs.Eq(opts.DstSrs)
+	toSrc := func(pts []vec2d.T) []vec2d.T {
+		if !reproject {
+			return pts
+		}
+		return opts.DstSrs.TransformTo(opts.SrcSrs, pts)
+	}
+
+	bounds := opts.Bounds
+	if bounds.Max[0] <= bounds.Min[0] || bounds.Max[1] <= bounds.Min[1] {
+		bounds = src.Extent()
+		if reproject {
+			bounds = opts.SrcSrs.TransformRectTo(opts.DstSrs, bounds, 16)
+		}
+	}
+	width, height := bounds.Max[0]-bounds.Min[0], bounds.Max[1]-bounds.Min[1]
+
+	cellSize, cellSizeY := opts.CellSize, opts.CellSizeY
+	if cellSizeY <= 0 {
+		cellSizeY = cellSize
+	}
+	if cellSize <= 0 || cellSizeY <= 0 {
+		cellSize, cellSizeY = src.CellSizeX(), src.CellSizeY()
+		if reproject {
+			srcBounds := opts.DstSrs.TransformRectTo(opts.SrcSrs, bounds, 16)
+			// 保持目标范围内的像元数与源栅格相同范围内的像元数一致
+			srcCols := (srcBounds.Max[0] - srcBounds.Min[0]) / src.CellSizeX()
+			srcRows := (srcBounds.Max[1] - srcBounds.Min[1]) / src.CellSizeY()
+			cellSize = width / math.Max(srcCols, 1)
+			cellSizeY = height / math.Max(srcRows, 1)
+		}
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid warp target: bounds=%v cellsize=%.6f", bounds, cellSize)
+	}
+
+	cols := max(1, int(math.Ceil(width/cellSize-EPS)))
+	rows := max(1, int(math.Ceil(height/cellSizeY-EPS)))
+	dst := NewRasterDouble(rows, cols, math.NaN())
+	dst.SetXYPosCellSize(bounds.Min[0], bounds.Max[1]-float64(rows)*cellSizeY, cellSize, cellSizeY)
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	parallelFor(workers, rows, func(r int) {
+		warpRow(src, dst, r, opts.Method, toSrc)
+	})
+	return dst, nil
+}
+
+// 计算目标栅格的一行，坐标变换按整行批量进行
+func warpRow(src, dst *RasterDouble, r int, method ResampleMethod, toSrc func([]vec2d.T) []vec2d.T) {
+	cols := dst.Cols()
+
+	if method == ResampleAverage {
+		// 变换像元上下两条边的角点，取四角点外包矩形作为源像素范围
+		edges := make([]vec2d.T, 0, 2*(cols+1))
+		for _, dr := range [2]float64{-0.5, 0.5} {
+			for c := 0; c <= cols; c++ {
+				x, y := dst.pixelToGeo(float64(c)-0.5, float64(r)+dr)
+				edges = append(edges, vec2d.T{x, y})
+			}
+		}
+		edges = toSrc(edges)
+		for c := 0; c < cols; c++ {
+			row0, col0 := math.Inf(1), math.Inf(1)
+			row1, col1 := math.Inf(-1), math.Inf(-1)
+			for _, p := range [4]vec2d.T{edges[c], edges[c+1], edges[cols+1+c], edges[cols+2+c]} {
+				pc, pr := src.geoToPixel(p[0], p[1])
+				row0, row1 = math.Min(row0, pr), math.Max(row1, pr)
+				col0, col1 = math.Min(col0, pc), math.Max(col1, pc)
+			}
+			dst.SetValue(r, c, sampleAverage(src, row0, col0, row1, col1))
+		}
+		return
+	}
+
+	centres := make([]vec2d.T, cols)
+	for c := range centres {
+		x, y := dst.pixelToGeo(float64(c), float64(r))
+		centres[c] = vec2d.T{x, y}
+	}
+	centres = toSrc(centres)
+	for c, p := range centres {
+		col, row := src.geoToPixel(p[0], p[1])
+		var v float64
+		switch method {
+		case ResampleNearest:
+			v = SampleNearest(src, row, col)
+		case ResampleCubic:
+			v = SampleCubic(src, row, col)
+		default:
+			v = SampleBilinear(src, row, col)
+		}
+		dst.SetValue(r, c, v)
+	}
+}
@@ -0,0 +1,206 @@
+package tin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flywave/go-geo"
+	vec2d "github.com/flywave/go3d/float64/vec2"
+)
+
+// 8x8 线性栅格 z = 3x + 2y，左下角 (0, 0)，分辨率 1
+func createLinearRaster() *RasterDouble {
+	raster := NewRasterDouble(8, 8, math.NaN())
+	raster.SetXYPos(0, 0, 1)
+	for r := 0; r < 8; r++ {
+		for c := 0; c < 8; c++ {
+			x, y := raster.pixelToGeo(float64(c), float64(r))
+			raster.SetValue(r, c, 3*x+2*y)
+		}
+	}
+	return raster
+}
+
+func TestWarpResample(t *testing.T) {
+	src := createLinearRaster()
+
+	for _, method := range []ResampleMethod{ResampleBilinear, ResampleCubic} {
+		dst, err := Warp(src, &WarpOptions{CellSize: 0.5, Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dst.Rows() != 16 || dst.Cols() != 16 {
+			t.Fatalf("目标尺寸错误: %dx%d", dst.Rows(), dst.Cols())
+		}
+		// 边缘半个像元之外，线性函数应被精确还原
+		for r := 1; r < 15; r++ {
+			for c := 1; c < 15; c++ {
+				x, y := dst.pixelToGeo(float64(c), float64(r))
+				if v := dst.Value(r, c); math.Abs(v-(3*x+2*y)) > 1e-9 {
+					t.Fatalf("方法 %d: (%d, %d) = %.6f, 期望 %.6f", method, r, c, v, 3*x+2*y)
+				}
+			}
+		}
+	}
+
+	nearest, err := Warp(src, &WarpOptions{CellSize: 0.5, Method: ResampleNearest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nearest.Value(0, 0) != src.Value(0, 0) || nearest.Value(5, 7) != src.Value(2, 3) {
+		t.Error("最邻近采样应取所在源像元的值")
+	}
+
+	src.SetValue(0, 0, math.NaN())
+	avg, err := Warp(src, &WarpOptions{CellSize: 2, Method: ResampleAverage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avg.Rows() != 4 || avg.Cols() != 4 {
+		t.Fatalf("目标尺寸错误: %dx%d", avg.Rows(), avg.Cols())
+	}
+	want := (src.Value(0, 1) + src.Value(1, 0) + src.Value(1, 1)) / 3
+	if v := avg.Value(0, 0); math.Abs(v-want) > 1e-9 {
+		t.Errorf("均值采样应跳过 NoData: %.6f, 期望 %.6f", v, want)
+	}
+	want = (src.Value(2, 4) + src.Value(2, 5) + src.Value(3, 4) + src.Value(3, 5)) / 4
+	if v := avg.Value(1, 2); math.Abs(v-want) > 1e-9 {
+		t.Errorf("均值采样: %.6f, 期望 %.6f", v, want)
+	}
+}
+
+func TestWarpWorkers(t *testing.T) {
+	src := createLinearRaster()
+	seq, err := Warp(src, &WarpOptions{CellSize: 0.25, Method: ResampleCubic, Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	par, err := Warp(src, &WarpOptions{CellSize: 0.25, Method: ResampleCubic, Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range seq.DataSlice() {
+		if w := par.DataSlice()[i]; v != w && !(math.IsNaN(v) && math.IsNaN(w)) {
+			t.Fatalf("像元 %d: 并行 %.6f, 顺序 %.6f", i, w, v)
+		}
+	}
+}
+
+// 非正方形源像元与目标像元
+func TestWarpNonSquare(t *testing.T) {
+	src := NewRasterDouble(4, 8, math.NaN())
+	src.SetXYPosCellSize(0, 0, 1, 2)
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 8; c++ {
+			x, y := src.pixelToGeo(float64(c), float64(r))
+			src.SetValue(r, c, 3*x+2*y)
+		}
+	}
+
+	// 默认沿用源像元大小
+	same, err := Warp(src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.Rows() != 4 || same.Cols() != 8 || same.CellSizeY() != 2 {
+		t.Fatalf("目标尺寸错误: %dx%d %v", same.Rows(), same.Cols(), same.GeoTransform())
+	}
+
+	dst, err := Warp(src, &WarpOptions{CellSize: 2, CellSizeY: 1, Method: ResampleBilinear})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Rows() != 8 || dst.Cols() != 4 {
+		t.Fatalf("目标尺寸错误: %dx%d", dst.Rows(), dst.Cols())
+	}
+	for r := 1; r < 7; r++ {
+		for c := 0; c < 4; c++ {
+			x, y := dst.pixelToGeo(float64(c), float64(r))
+			if v := dst.Value(r, c); math.Abs(v-(3*x+2*y)) > 1e-9 {
+				t.Fatalf("(%d,%d) 插值错误: %.6f, 期望 %.6f", r, c, v, 3*x+2*y)
+			}
+		}
+	}
+}
+
+func TestWarpOutside(t *testing.T) {
+	src := createLinearRaster()
+	dst, err := Warp(src, &WarpOptions{
+		Bounds:   vec2d.Rect{Min: vec2d.T{6, 6}, Max: vec2d.T{12, 12}},
+		CellSize: 1,
+		Method:   ResampleBilinear,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(dst.Value(0, 5)) {
+		t.Error("源栅格范围外应为 NoData")
+	}
+	if v := dst.Value(5, 0); math.Abs(v-(3*6.5+2*6.5)) > 1e-9 {
+		t.Errorf("重叠部分取值错误: %.6f", v)
+	}
+
+	if _, err := Warp(nil, nil); err == nil {
+		t.Error("nil 栅格应返回错误")
+	}
+}
+
+func TestWarpReproject(t *testing.T) {
+	wgs84 := geo.NewProj(4326)
+	merc := geo.NewProj(3857)
+
+	// 经纬度栅格 z = 10 * lon + 5 * lat
+	src := NewRasterDouble(32, 32, math.NaN())
+	src.SetXYPos(116, 39, 1.0/32)
+	for r := 0; r < 32; r++ {
+		for c := 0; c < 32; c++ {
+			lon, lat := src.pixelToGeo(float64(c), float64(r))
+			src.SetValue(r, c, 10*lon+5*lat)
+		}
+	}
+
+	dst, err := Warp(src, &WarpOptions{SrcSrs: wgs84, DstSrs: merc, Method: ResampleBilinear})
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := 0
+	for r := 0; r < dst.Rows(); r++ {
+		for c := 0; c < dst.Cols(); c++ {
+			v := dst.Value(r, c)
+			if math.IsNaN(v) {
+				continue
+			}
+			valid++
+			x, y := dst.pixelToGeo(float64(c), float64(r))
+			p := merc.TransformTo(wgs84, []vec2d.T{{x, y}})[0]
+			// 源栅格边缘半个像元内双线性插值取边缘值，不做比较
+			if sc, sr := src.geoToPixel(p[0], p[1]); sc < 0 || sr < 0 || sc > 31 || sr > 31 {
+				continue
+			}
+			if want := 10*p[0] + 5*p[1]; math.Abs(v-want) > 1e-6 {
+				t.Fatalf("(%d, %d) = %.6f, 期望 %.6f", r, c, v, want)
+			}
+		}
+	}
+	if valid < dst.Rows()*dst.Cols()/2 {
+		t.Errorf("有效像元过少: %d/%d", valid, dst.Rows()*dst.Cols())
+	}
+}
+
+func TestRasterAdapterWarp(t *testing.T) {
+	src := createLinearRaster()
+	bbox := vec2d.Rect{Min: vec2d.T{0, 0}, Max: vec2d.T{8, 8}}
+	adapter := NewRasterAdapter(geo.NewMercTileGrid(), geo.NewBBoxCoverage(bbox, geo.NewProj(4326), true), src)
+	if adapter.originSR.Eq(adapter.tileGrid.Srs) {
+		t.Fatal("源栅格坐标系应取自 coverage")
+	}
+
+	tile := vec2d.Rect{Min: vec2d.T{2, 2}, Max: vec2d.T{4, 4}}
+	dem, err := adapter.GetDEM(tile, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dem.West() != 2 || math.Abs(dem.North()-4) > 1e-9 {
+		t.Errorf("瓦片范围错误: %v", dem.Bounds)
+	}
+}
@@ -3,8 +3,7 @@ package tin
 import (
 	"fmt"
 	"math"
-
-	vec2d "github.com/flywave/go3d/float64/vec2"
+	"runtime"
 
 	"github.com/flywave/go-geo"
 	"github.com/flywave/go-geoid"
@@ -35,8 +34,8 @@ type GeoConfig struct {
 // https://isprs-archives.copernicus.org/articles/XLI-B2/459/2016/isprs-archives-XLI-B2-459-2016.pdf
 type ZemlyaMesh struct {
 	RasterMesh
-	Sample       *RasterDouble
-	Insert       *RasterDouble
+	Sample       *RasterFloat // 低层级使用的重采样高程，仅用于候选点排序，单精度即可
+	Insert       *RasterFloat
 	Result       *RasterDouble
 	Used         *RasterChar
 	Token        *RasterInt
@@ -45,6 +44,30 @@ type ZemlyaMesh struct {
 	Counter      int
 	CurrentLevel int
 	MaxLevel     int
+
+	// 顶点/三角形预算，0 表示不限制；与 MaxError 同时生效，先达到者终止插入
+	MaxVertices   int
+	MaxTriangles  int
+	VertexCount   int
+	AchievedError float64 // 插入结束后网格相对原始栅格的实际最大误差
+
+	// 插入前填充栅格 NoData 的方式，nil 表示不填充
+	// 填充结果只用于本次插入，加载的栅格保持不变
+	VoidFill *VoidFillOptions
+	// 最近一次插入前填充失败的原因，失败时按未填充的栅格插入
+	VoidFillErr error
+
+	// 候选点重要性函数，决定三角形内选取哪个点及插入顺序，nil 时为垂直误差
+	// 并行扫描时会被多个协程同时调用
+	Importance ImportanceFunc
+
+	// 重采样与三角形扫描的并行协程数，0 表示 GOMAXPROCS，1 表示顺序执行
+	Workers int
+
+	// 并行扫描复用的缓冲区
+	scanRows       []scanRow
+	scanTasks      []scanTask
+	scanCandidates []*Candidate
 }
 
 func NewZemlyaMesh(config *GeoConfig) *ZemlyaMesh {
@@ -55,21 +78,40 @@ func NewZemlyaMesh(config *GeoConfig) *ZemlyaMesh {
 			Offset:  config.Offset,
 		},
 	}
-	mesh.QuadEdges = NewPool(func() interface{} { return &QuadEdge{} })
-	mesh.Triangles = NewPool(func() interface{} { return &DelaunayTriangle{} })
+	mesh.QuadEdges = NewEdgeArena()
+	mesh.Triangles = NewArena[DelaunayTriangle]()
 	mesh.scanTriangle = mesh.ScanTriangle
+	mesh.scanTriangles = mesh.scanTriangleBatch
 	return mesh
 }
 
+// 像元 X/Y 方向地面长度之比，经纬度栅格按中心纬度换算经度方向长度
+func (z *ZemlyaMesh) groundAspect() float64 {
+	grid := z.grid()
+	sx, sy := grid.CellSizeX(), grid.CellSizeY()
+	if sx <= 0 || sy <= 0 {
+		return 1
+	}
+	if z.SrcProj != nil && z.SrcProj.IsLatLong() {
+		_, lat := grid.pixelToGeo(float64(grid.Cols()-1)/2, float64(grid.Rows()-1)/2)
+		if math.Abs(lat) <= 90 {
+			// 极点附近限制比例，避免外接圆判定退化
+			sx *= math.Max(math.Cos(lat*math.Pi/180), 0.01)
+		}
+	}
+	return sx / sy
+}
+
 func (z *ZemlyaMesh) LoadRaster(raster *RasterDouble) error {
 	if raster == nil {
 		return fmt.Errorf("nil raster")
 	}
-	z.Raster = raster
+	z.RasterMesh.LoadRaster(raster)
 	return nil
 }
 
-func (z *ZemlyaMesh) scanTriangleLine(plane Plane, y int, x1, x2 float64, candidate *Candidate, noDataValue float64) {
+// sample 为 nil 时以垂直误差作为重要性
+func (z *ZemlyaMesh) scanTriangleLine(plane Plane, y int, x1, x2 float64, candidate *Candidate, sample *ImportanceSample, noDataValue float64) {
 	startx := int(math.Ceil(math.Min(x1, x2)))
 	endx := int(math.Floor(math.Max(x1, x2)))
 
@@ -80,7 +122,8 @@ func (z *ZemlyaMesh) scanTriangleLine(plane Plane, y int, x1, x2 float64, candid
 	z0 := plane.Eval(float64(startx), float64(y))
 	dz := plane[0]
 
-	for x := startx; x <= endx; x++ {
+	// 跳过已使用单元时平面高程也必须步进
+	for x := startx; x <= endx; x, z0 = x+1, z0+dz {
 		if z.Used.Value(y, x) != 0 {
 			continue
 		}
@@ -89,102 +132,80 @@ func (z *ZemlyaMesh) scanTriangleLine(plane Plane, y int, x1, x2 float64, candid
 		if z.CurrentLevel == z.MaxLevel {
 			zv = z.getElevation(y, x)
 		} else {
-			zv = z.Insert.Value(y, x)
+			zv = z.Insert.Elevation(y, x)
 		}
 
 		if !isNoData(zv, noDataValue) {
 			diff := math.Abs(zv - z0)
-			candidate.Consider(x, y, zv, diff)
+			candidate.Error = math.Max(candidate.Error, diff)
+			if sample != nil {
+				sample.Row, sample.Col, sample.Z, sample.Error = y, x, zv, zv-z0
+				candidate.Consider(x, y, zv, z.Importance(sample))
+			} else {
+				candidate.Consider(x, y, zv, diff)
+			}
 		}
-		z0 += dz
 	}
 }
 
+// 在预算限制下执行贪婪插入，maxVertices/maxTriangles 为 0 时不限制
+func (z *ZemlyaMesh) GreedyInsertWithBudget(maxError float64, maxVertices, maxTriangles int) {
+	z.MaxVertices = maxVertices
+	z.MaxTriangles = maxTriangles
+	z.GreedyInsert(maxError)
+}
+
+// 插入下一个点是否会超出当前层级的顶点或三角形预算
+// 低层级使用重采样近似高程，只分配 level/MaxLevel 比例的预算，
+// 剩余预算留给按原始高程排序的最高层级
+// 内部插入一个点增加两个三角形，边界上增加一个，这里按最坏情况估计
+func (z *ZemlyaMesh) budgetExhausted(level int) bool {
+	share := func(budget int) int {
+		return budget * level / z.MaxLevel
+	}
+	if z.MaxVertices > 0 && z.VertexCount+1 > share(z.MaxVertices) {
+		return true
+	}
+	if z.MaxTriangles > 0 && z.Triangles.Len()+2 > share(z.MaxTriangles) {
+		return true
+	}
+	return false
+}
+
+// 按重要性从大到小插入候选点，直到网格最大误差不超过 maxError
+// 候选队列为最大堆，扫描线跳过已使用单元时平面高程仍逐列步进；
+// 早期版本按入队顺序出队且跳过单元时平面不步进，相同输入生成的网格与之不同
 func (z *ZemlyaMesh) GreedyInsert(maxError float64) {
 	z.MaxError = maxError
 	z.Counter = 0
-	w := z.Raster.Cols()
-	h := z.Raster.Rows()
-	noDataValue := z.Raster.NoData.(float64)
+	z.VertexCount = 0
+	z.AchievedError = 0
+	z.Candidates.Clear()
+	z.filled = nil
+	z.VoidFillErr = nil
+	if z.VoidFill != nil && z.VoidFill.Method != VoidFillNone {
+		if err := z.VoidFill.validate(); err != nil {
+			z.VoidFillErr = err
+		} else if z.filled, err = FillVoids(elevationToDouble(z.elevation()), z.VoidFill); err != nil {
+			z.VoidFillErr = err
+		}
+	}
+	w := z.grid().Cols()
+	h := z.grid().Rows()
+	noDataValue := z.noData()
 
 	if w > h {
 		z.MaxLevel = int(math.Ceil(math.Log2(float64(w))))
 	} else {
 		z.MaxLevel = int(math.Ceil(math.Log2(float64(h))))
 	}
-	z.Sample = NewRasterDouble(h, w, noDataValue)
+	z.Sample = NewRasterOf(h, w, float32(noDataValue))
 
 	for level := z.MaxLevel - 1; level >= 1; level-- {
 		step := z.MaxLevel - level
-		for y := 0; y < h; y += int(math.Pow(2., float64(step))) {
-			for x := 0; x < w; x += int(math.Pow(2., float64(step))) {
-				if step == 1 {
-					var v1 float64
-					if y < h && x < w {
-						v1 = z.getElevation(y, x)
-					} else {
-						v1 = math.NaN()
-					}
-					var v2 float64
-					if y < h && x+1 < w {
-						v2 = z.getElevation(y, x+1)
-					} else {
-						v2 = math.NaN()
-					}
-					var v3 float64
-					if y+1 < h && x < w {
-						v3 = z.getElevation(y+1, x)
-					} else {
-						v3 = math.NaN()
-					}
-					var v4 float64
-					if y+1 < h && x+1 < w {
-						v4 = z.getElevation(y+1, x+1)
-					} else {
-						v4 = math.NaN()
-					}
-
-					if y+1 < h && x+1 < w {
-						z.Sample.SetValue(y+1, x+1, averageOf(v1, v2, v3, v4, noDataValue))
-					}
-				} else {
-					co := int(math.Pow(2., float64(step)-1))
-					d := int(math.Pow(2., float64(step)-2))
-
-					var v1 float64
-					if y+co-d < h && x+co-d < w {
-						v1 = z.getElevation(y+co-d, x+co-d)
-					} else {
-						v1 = math.NaN()
-					}
-
-					var v2 float64
-					if y+co-d < h && x+co+d < w {
-						v2 = z.getElevation(y+co-d, x+co+d)
-					} else {
-						v2 = math.NaN()
-					}
-
-					var v3 float64
-					if y+co+d < h && x+co-d < w {
-						v3 = z.getElevation(y+co+d, x+co-d)
-					} else {
-						v3 = math.NaN()
-					}
-
-					var v4 float64
-					if y+co+d < h && x+co+d < w {
-						v4 = z.getElevation(y+co+d, x+co+d)
-					} else {
-						v4 = math.NaN()
-					}
-
-					if y+co < h && x+co < w {
-						z.Sample.SetValue(y+co, x+co, averageOf(v1, v2, v3, v4, noDataValue))
-					}
-				}
-			}
-		}
+		z.forEachStride(int(math.Pow(2., float64(step))), func(y, x int) {
+			z.sampleCell(y, x, w, h, step, noDataValue)
+		})
 	}
 
 	z.repairPoint(0, 0)
@@ -193,113 +214,62 @@ func (z *ZemlyaMesh) GreedyInsert(maxError float64) {
 	z.repairPoint(float64(w-1), 0)
 
 	z.Result = NewRasterDouble(h, w, noDataValue)
-	z.Result.Hemlines = z.Raster.Hemlines
+	z.Result.Hemlines = z.grid().Hemlines
 	z.Result.SetValue(0, 0, z.getElevation(0, 0))
 	z.Result.SetValue(h-1, 0, z.getElevation(h-1, 0))
 	z.Result.SetValue(h-1, w-1, z.getElevation(h-1, w-1))
 	z.Result.SetValue(0, w-1, z.getElevation(0, w-1))
 
-	z.Insert = NewRasterDouble(h, w, noDataValue)
+	z.Insert = NewRasterOf(h, w, float32(noDataValue))
 
 	z.Used = NewRasterChar(h, w, 0)
 	z.Token = NewRasterInt(h, w, 0)
 
+	z.aspect = z.groundAspect()
 	z.initMesh([2]float64{0, 0}, [2]float64{0, float64(h - 1)}, [2]float64{float64(w - 1), float64(h - 1)},
 		[2]float64{float64(w - 1), 0})
+	z.VertexCount = 4
 
+	exhausted := false
 	for level := 1; level <= z.MaxLevel; level++ {
 		z.CurrentLevel = level
-		z.Used = NewRasterChar(h, w, 0)
+		z.Used.Fill(0)
 
 		if level >= 5 && level <= z.MaxLevel-1 {
 			step := z.MaxLevel - level
 
-			for y := 0; y < h; y++ {
-				for x := 0; x < w; x++ {
-					zv := z.Insert.Value(y, x)
-					if isNoData(zv, noDataValue) {
-						continue
-					}
-					z.Insert.SetValue(y, x, z.getElevation(y, x))
+			z.forEachStride(1, func(y, x int) {
+				zv := z.Insert.Elevation(y, x)
+				if !isNoData(zv, noDataValue) {
+					z.Insert.SetElevation(y, x, z.getElevation(y, x))
 				}
-			}
+			})
 
-			for y := 0; y < h; y += int(math.Pow(2., float64(step))) {
-				for x := 0; x < w; x += int(math.Pow(2., float64(step))) {
-					co := int(math.Pow(2., float64(step)-1))
-					if y+co < h && x+co < w {
-						z.Insert.SetValue(y+co, x+co, z.getElevation(y+co, x+co))
-					}
+			co := int(math.Pow(2., float64(step)-1))
+			z.forEachStride(int(math.Pow(2., float64(step))), func(y, x int) {
+				if y+co < h && x+co < w {
+					z.Insert.SetElevation(y+co, x+co, z.getElevation(y+co, x+co))
 				}
-			}
+			})
 		} else if level < z.MaxLevel {
 			step := z.MaxLevel - level
 
 			if step >= 3 {
 				d := int(math.Pow(2., float64(step)-3))
-
-				for y := 0; y < h; y++ {
-					for x := 0; x < w; x++ {
-						zv := z.Insert.Value(y, x)
-						if isNoData(zv, noDataValue) {
-							continue
-						}
-
-						var v1 float64
-						if y-d < h && x-d < w {
-							v1 = z.Sample.Value(y-d, x-d)
-						} else {
-							v1 = math.NaN()
-						}
-
-						var v2 float64
-						if y-d < h && x+d < w {
-							v2 = z.Sample.Value(y-d, x+d)
-						} else {
-							v2 = math.NaN()
-						}
-
-						var v3 float64
-						if y+d < h && x-d < w {
-							v3 = z.Sample.Value(y+d, x-d)
-						} else {
-							v3 = math.NaN()
-						}
-
-						var v4 float64
-						if y+d < h && x+d < w {
-							v4 = z.Sample.Value(y+d, x+d)
-						} else {
-							v4 = math.NaN()
-						}
-
-						avg := averageOf(v1, v2, v3, v4, noDataValue)
-						if isNoData(avg, noDataValue) {
-							continue
-						}
-						z.Insert.SetValue(y, x, avg)
-					}
-				}
+				z.forEachStride(1, func(y, x int) {
+					z.refineInsertCell(y, x, w, h, d, noDataValue)
+				})
 			}
 
-			for y := 0; y < h; y += int(math.Pow(2., float64(step))) {
-				for x := 0; x < w; x += int(math.Pow(2., float64(step))) {
-					co := int(math.Pow(2., float64(step)-1))
-					if y+co < h && x+co < w {
-						z.Insert.SetValue(y+co, x+co, z.Sample.Value(y+co, x+co))
-					}
+			co := int(math.Pow(2., float64(step)-1))
+			z.forEachStride(int(math.Pow(2., float64(step))), func(y, x int) {
+				if y+co < h && x+co < w {
+					z.Insert.SetElevation(y+co, x+co, z.Sample.Elevation(y+co, x+co))
 				}
-			}
+			})
 		}
 
-		t := z.firstFace
-		for {
-			z.ScanTriangle(t)
-			t = t.GetLink()
-			if t == nil {
-				break
-			}
-		}
+		z.scanAllTriangles()
 
 		for {
 			if z.Candidates.Empty() {
@@ -307,7 +277,7 @@ func (z *ZemlyaMesh) GreedyInsert(maxError float64) {
 			}
 			candidate := z.Candidates.GrabGreatest()
 
-			if candidate.Importance < z.MaxError {
+			if candidate.Error < z.MaxError {
 				continue
 			}
 
@@ -315,22 +285,358 @@ func (z *ZemlyaMesh) GreedyInsert(maxError float64) {
 				continue
 			}
 
-			z.Result.SetValue(candidate.Y, candidate.X, candidate.Z)
+			if z.budgetExhausted(level) {
+				exhausted = level == z.MaxLevel
+				z.Candidates.Clear()
+				break
+			}
+
+			// 低层级插入过的点在高层级会以原始高程再次插入，此时不增加顶点
+			if isNoData(z.Result.Value(candidate.Y, candidate.X), noDataValue) {
+				z.VertexCount++
+			}
+			zv := candidate.Z
+			if level >= 5 {
+				// 第 5 层起 Insert 以单精度保存原始高程，结果取回双精度值
+				zv = z.getElevation(candidate.Y, candidate.X)
+			}
+			z.Result.SetValue(candidate.Y, candidate.X, zv)
 			z.Used.SetValue(candidate.Y, candidate.X, 1)
 
 			z.insert([2]float64{float64(candidate.X), float64(candidate.Y)}, candidate.Triangle)
 
 		}
 	}
+
+	// 预算耗尽时低层级插入的顶点高程可能仍是重采样近似值，改用原始高程
+	if exhausted {
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if !isNoData(z.Result.Value(y, x), noDataValue) {
+					z.Result.SetValue(y, x, z.getElevation(y, x))
+				}
+			}
+		}
+	}
+
+	z.AchievedError = z.measureError()
+}
+
+// 计算低层级重采样高程，只写入 (y, x) 所在步长块内的单元
+func (z *ZemlyaMesh) sampleCell(y, x, w, h, step int, noDataValue float64) {
+	if step == 1 {
+		var v1 float64
+		if y < h && x < w {
+			v1 = z.getElevation(y, x)
+		} else {
+			v1 = math.NaN()
+		}
+		var v2 float64
+		if y < h && x+1 < w {
+			v2 = z.getElevation(y, x+1)
+		} else {
+			v2 = math.NaN()
+		}
+		var v3 float64
+		if y+1 < h && x < w {
+			v3 = z.getElevation(y+1, x)
+		} else {
+			v3 = math.NaN()
+		}
+		var v4 float64
+		if y+1 < h && x+1 < w {
+			v4 = z.getElevation(y+1, x+1)
+		} else {
+			v4 = math.NaN()
+		}
+
+		if y+1 < h && x+1 < w {
+			z.Sample.SetElevation(y+1, x+1, averageOf(v1, v2, v3, v4, noDataValue))
+		}
+	} else {
+		co := int(math.Pow(2., float64(step)-1))
+		d := int(math.Pow(2., float64(step)-2))
+
+		var v1 float64
+		if y+co-d < h && x+co-d < w {
+			v1 = z.getElevation(y+co-d, x+co-d)
+		} else {
+			v1 = math.NaN()
+		}
+
+		var v2 float64
+		if y+co-d < h && x+co+d < w {
+			v2 = z.getElevation(y+co-d, x+co+d)
+		} else {
+			v2 = math.NaN()
+		}
+
+		var v3 float64
+		if y+co+d < h && x+co-d < w {
+			v3 = z.getElevation(y+co+d, x+co-d)
+		} else {
+			v3 = math.NaN()
+		}
+
+		var v4 float64
+		if y+co+d < h && x+co+d < w {
+			v4 = z.getElevation(y+co+d, x+co+d)
+		} else {
+			v4 = math.NaN()
+		}
+
+		if y+co < h && x+co < w {
+			z.Sample.SetElevation(y+co, x+co, averageOf(v1, v2, v3, v4, noDataValue))
+		}
+	}
+}
+
+// 以 Sample 中相距 d 的四个对角单元均值更新 Insert 中已有的单元
+func (z *ZemlyaMesh) refineInsertCell(y, x, w, h, d int, noDataValue float64) {
+	zv := z.Insert.Elevation(y, x)
+	if isNoData(zv, noDataValue) {
+		return
+	}
+
+	var v1 float64
+	if y-d < h && x-d < w {
+		v1 = z.Sample.Elevation(y-d, x-d)
+	} else {
+		v1 = math.NaN()
+	}
+
+	var v2 float64
+	if y-d < h && x+d < w {
+		v2 = z.Sample.Elevation(y-d, x+d)
+	} else {
+		v2 = math.NaN()
+	}
+
+	var v3 float64
+	if y+d < h && x-d < w {
+		v3 = z.Sample.Elevation(y+d, x-d)
+	} else {
+		v3 = math.NaN()
+	}
+
+	var v4 float64
+	if y+d < h && x+d < w {
+		v4 = z.Sample.Elevation(y+d, x+d)
+	} else {
+		v4 = math.NaN()
+	}
+
+	avg := averageOf(v1, v2, v3, v4, noDataValue)
+	if isNoData(avg, noDataValue) {
+		return
+	}
+	z.Insert.SetElevation(y, x, avg)
+}
+
+// 逐行并行遍历行列均为 stride 整数倍的单元，fn 只能写入互不重叠的单元
+func (z *ZemlyaMesh) forEachStride(stride int, fn func(y, x int)) {
+	w := z.grid().Cols()
+	h := z.grid().Rows()
+	parallelFor(z.workers(), (h+stride-1)/stride, func(i int) {
+		y := i * stride
+		for x := 0; x < w; x += stride {
+			fn(y, x)
+		}
+	})
+}
+
+func (z *ZemlyaMesh) workers() int {
+	if z.Workers > 0 {
+		return z.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// 扫描当前全部三角形
+func (z *ZemlyaMesh) scanAllTriangles() {
+	var triangles []*DelaunayTriangle
+	for t := z.firstFace; t != nil; t = t.GetLink() {
+		triangles = append(triangles, t)
+	}
+	z.scanTriangleBatch(triangles)
+}
+
+// 总面积低于该值的一批三角形顺序扫描，并行调度的开销高于收益
+const parallelScanCells = 4096
+
+// 并行扫描时每个任务的单元数，面积更大的三角形按行拆分为多个任务
+const scanTaskCells = 1024
+
+// 三角形中的一行，x1/x2 为顺序扫描时累加得到的左右边界
+type scanRow struct {
+	y      int
+	x1, x2 float64
+}
+
+// 并行扫描的任务：整个三角形，或大三角形中连续的若干行
+type scanTask struct {
+	tri              int
+	rowStart, rowEnd int // 在 scanRows 中的行范围，rowEnd 为 0 表示整个三角形
+	part             *Candidate
+}
+
+// 依次扫描 triangles 并入队候选点，令牌按顺序分配
+// 面积足够大时按三角形与行块并行求取候选点，再按扫描顺序合并，结果与顺序扫描一致
+func (z *ZemlyaMesh) scanTriangleBatch(triangles []*DelaunayTriangle) {
+	workers := z.workers()
+	area := 0.0
+	if workers > 1 {
+		for _, t := range triangles {
+			area += math.Abs(triArea(t.point1(), t.point2(), t.point3())) / 2
+		}
+	}
+	if workers <= 1 || area < parallelScanCells {
+		for _, t := range triangles {
+			z.ScanTriangle(t)
+		}
+		return
+	}
+
+	if cap(z.scanCandidates) < len(triangles) {
+		z.scanCandidates = make([]*Candidate, len(triangles))
+	}
+	candidates := z.scanCandidates[:len(triangles)]
+	tasks := z.scanTasks[:0]
+	rows := z.scanRows[:0]
+	for i, t := range triangles {
+		token := z.Counter + i
+		if math.Abs(triArea(t.point1(), t.point2(), t.point3()))/2 <= scanTaskCells {
+			tasks = append(tasks, scanTask{tri: i})
+			continue
+		}
+		_, byy, candidate, _ := z.prepareScan(t, token)
+		first := len(rows)
+		forTriangleRows(byy, func(y int, x1, x2 float64) {
+			rows = append(rows, scanRow{y, x1, x2})
+		})
+		candidates[i] = candidate
+		// 行块以下标记录，rows 扩容后仍有效
+		for start := first; start < len(rows); {
+			end, cells := start, 0.0
+			for end < len(rows) && cells < scanTaskCells {
+				cells += math.Abs(rows[end].x2-rows[end].x1) + 1
+				end++
+			}
+			tasks = append(tasks, scanTask{tri: i, rowStart: start, rowEnd: end})
+			start = end
+		}
+	}
+	z.scanRows, z.scanTasks = rows, tasks
+
+	noDataValue := z.noData()
+	parallelFor(workers, len(tasks), func(i int) {
+		task := &tasks[i]
+		t := triangles[task.tri]
+		if task.rowEnd == 0 {
+			candidates[task.tri] = z.findCandidate(t, z.Counter+task.tri)
+			return
+		}
+		plane, _, part, sample := z.prepareScan(t, 0)
+		for _, r := range rows[task.rowStart:task.rowEnd] {
+			z.scanTriangleLine(plane, r.y, r.x1, r.x2, part, sample, noDataValue)
+		}
+		task.part = part
+	})
+
+	// 行块按扫描顺序合并，重要性相同时保留先扫描到的单元
+	for _, task := range tasks {
+		if task.part == nil {
+			continue
+		}
+		c := candidates[task.tri]
+		c.Error = math.Max(c.Error, task.part.Error)
+		c.Consider(task.part.X, task.part.Y, task.part.Z, task.part.Importance)
+	}
+	z.Counter += len(triangles)
+	for _, candidate := range candidates {
+		z.addCandidate(candidate)
+	}
+	clear(candidates)
+}
+
+// 遍历三角剖分覆盖的有效栅格单元，fn 收到三角形插值高程与原始高程
+func (z *ZemlyaMesh) scanResultCells(fn func(row, col int, tinZ, demZ float64)) {
+	for t := z.firstFace; t != nil; t = t.GetLink() {
+		z.scanTriangleResultCells(t, fn)
+	}
+}
+
+// 遍历三角形 t 覆盖的有效栅格单元，只读取栅格与网格，可并发调用
+func (z *ZemlyaMesh) scanTriangleResultCells(t *DelaunayTriangle, fn func(row, col int, tinZ, demZ float64)) {
+	noDataValue := z.noData()
+	var p [3][3]float64
+	for i, pt := range [3][2]float64{t.point1(), t.point2(), t.point3()} {
+		p[i] = [3]float64{pt[0], pt[1], z.Result.Value(int(pt[1]), int(pt[0]))}
+	}
+	scanTriangleCells(p, z.grid().Rows(), z.grid().Cols(), func(row, col int, tinZ float64) {
+		v := z.getElevation(row, col)
+		if !isNoData(v, noDataValue) && !math.IsNaN(tinZ) {
+			fn(row, col, tinZ, v)
+		}
+	})
+}
+
+// 以原始高程重新扫描全部三角形，返回网格的实际最大垂直误差，各三角形并行扫描
+func (z *ZemlyaMesh) measureError() float64 {
+	var triangles []*DelaunayTriangle
+	for t := z.firstFace; t != nil; t = t.GetLink() {
+		triangles = append(triangles, t)
+	}
+	errs := make([]float64, len(triangles))
+	parallelFor(z.workers(), len(triangles), func(i int) {
+		z.scanTriangleResultCells(triangles[i], func(row, col int, tinZ, demZ float64) {
+			errs[i] = math.Max(errs[i], math.Abs(tinZ-demZ))
+		})
+	})
+	maxErr := 0.0
+	for _, e := range errs {
+		maxErr = math.Max(maxErr, e)
+	}
+	return maxErr
 }
 
 func (z *ZemlyaMesh) ScanTriangle(t *DelaunayTriangle) {
+	candidate := z.findCandidate(t, z.Counter)
+	z.Counter++
+	z.addCandidate(candidate)
+}
+
+// 在三角形内按重要性选取候选点，只读取栅格与网格，可并发调用
+func (z *ZemlyaMesh) findCandidate(t *DelaunayTriangle, token int) *Candidate {
+	zPlane, byy, candidate, sample := z.prepareScan(t, token)
+	noDataValue := z.noData()
+	forTriangleRows(byy, func(y int, x1, x2 float64) {
+		z.scanTriangleLine(zPlane, y, x1, x2, candidate, sample, noDataValue)
+	})
+	return candidate
+}
+
+// 三角形所在平面、按 y 排序的顶点、空候选点，以及设置重要性函数时使用的采样信息
+func (z *ZemlyaMesh) prepareScan(t *DelaunayTriangle, token int) (Plane, [3][2]float64, *Candidate, *ImportanceSample) {
 	zPlane := computePlane(t, z.Result)
 
 	byy := [3][2]float64{t.point1(), t.point2(), t.point3()}
 
 	orderTrianglePoints(&byy)
 
+	candidate := &Candidate{X: 0, Y: 0, Z: 0.0, Importance: -math.MaxFloat64, Token: token, Triangle: t}
+
+	var sample *ImportanceSample
+	if z.Importance != nil {
+		grid := z.grid()
+		area := math.Abs(triArea(byy[0], byy[1], byy[2])) / 2 * grid.CellSizeX() * grid.CellSizeY()
+		sample = &ImportanceSample{Plane: zPlane, Area: area, mesh: z}
+	}
+	return zPlane, byy, candidate, sample
+}
+
+// 按扫描顺序给出三角形覆盖的每一行及其左右边界，byy 为按 y 排序的顶点
+func forTriangleRows(byy [3][2]float64, fn func(y int, x1, x2 float64)) {
 	v0X := byy[0][0]
 	v0Y := byy[0][1]
 	v1X := byy[1][0]
@@ -338,10 +644,7 @@ func (z *ZemlyaMesh) ScanTriangle(t *DelaunayTriangle) {
 	v2X := byy[2][0]
 	v2Y := byy[2][1]
 
-	candidate := &Candidate{X: 0, Y: 0, Z: 0.0, Importance: -math.MaxFloat64, Token: z.Counter, Triangle: t}
-	z.Counter++
 	dx2 := (v2X - v0X) / (v2Y - v0Y)
-	noDataValue := z.Raster.NoData.(float64)
 
 	if v1Y != v0Y {
 		dx1 := (v1X - v0X) / (v1Y - v0Y)
@@ -353,7 +656,7 @@ func (z *ZemlyaMesh) ScanTriangle(t *DelaunayTriangle) {
 		endy := int(v1Y)
 
 		for y := starty; y <= endy; y++ {
-			z.scanTriangleLine(zPlane, y, x1, x2, candidate, noDataValue)
+			fn(y, x1, x2)
 			x1 += dx1
 			x2 += dx2
 		}
@@ -369,26 +672,30 @@ func (z *ZemlyaMesh) ScanTriangle(t *DelaunayTriangle) {
 		endy := int(v2Y)
 
 		for y := starty; y <= endy; y++ {
-			z.scanTriangleLine(zPlane, y, x1, x2, candidate, noDataValue)
+			fn(y, x1, x2)
 			x1 += dx1
 			x2 += dx2
 		}
 	}
+}
 
+// 记录候选点令牌，误差超过阈值时加入候选队列
+func (z *ZemlyaMesh) addCandidate(candidate *Candidate) {
 	z.Token.SetValue(candidate.Y, candidate.X, int32(candidate.Token))
-	if candidate.Importance >= z.MaxError {
+	if candidate.Importance > -math.MaxFloat64 && candidate.Error >= z.MaxError {
 		z.Candidates.Push(candidate)
 	}
 }
 
 func (z *ZemlyaMesh) ToMesh() *Mesh {
-	w := z.Raster.Cols()
-	h := z.Raster.Rows()
+	grid := z.grid()
+	w := grid.Cols()
+	h := grid.Rows()
 
 	var mvertices []Vertex
 
 	vertexID := NewRasterInt(h, w, 0)
-	noDataValue := z.Raster.NoData.(float64)
+	noDataValue := z.noData()
 
 	index := 0
 	minx := math.MaxFloat64
@@ -403,9 +710,10 @@ func (z *ZemlyaMesh) ToMesh() *Mesh {
 		for x := 0; x < w; x++ {
 			zv := z.Result.Value(y, x)
 			if !isNoData(zv, noDataValue) {
-				v := Vertex{z.Raster.ColToX(x), z.Raster.RowToY(y), zv}
-				if z.Raster.transform != nil {
-					v = z.Raster.transform(&v)
+				cx, cy := grid.CellCenter(y, x)
+				v := Vertex{cx, cy, zv}
+				if grid.transform != nil {
+					v = grid.transform(&v)
 				}
 				minx = math.Min(minx, v[0])
 				miny = math.Min(miny, v[1])
@@ -494,10 +802,7 @@ func (z *ZemlyaMesh) ToMesh() *Mesh {
 	}
 
 	mesh := &Mesh{
-		GeoRef: geo.NewGeoReference(vec2d.Rect{
-			Min: vec2d.T{z.Raster.Bounds[0], z.Raster.Bounds[1]},
-			Max: vec2d.T{z.Raster.Bounds[2], z.Raster.Bounds[3]},
-		}, z.SrcProj),
+		GeoRef: geo.NewGeoReference(grid.Extent(), z.SrcProj),
 	}
 	mesh.BBox[0] = [3]float64{minx, miny, minz}
 	mesh.BBox[1] = [3]float64{maxx, maxy, maxz}
@@ -1,6 +1,7 @@
 package tin
 
 import (
+	"hash/fnv"
 	"math"
 	"testing"
 )
@@ -112,8 +113,8 @@ func TestZemlyaMeshIntegration(t *testing.T) {
 		}
 	}
 
-	expectedWest := testRaster.West() + testRaster.cellsize/2
-	expectedEast := testRaster.East() - testRaster.cellsize/2
+	expectedWest := testRaster.West() + testRaster.CellSize()/2
+	expectedEast := testRaster.East() - testRaster.CellSize()/2
 
 	if math.Abs(westmost-expectedWest) > 1e-5 || math.Abs(eastmost-expectedEast) > 1e-5 {
 		t.Errorf("边界范围错误: 西界=%.2f (应为%.2f), 东界=%.2f (应为%.2f)",
@@ -138,3 +139,371 @@ func TestZemlyaMeshIntegration(t *testing.T) {
 		t.Errorf("有效点(2,2)被错误标记为无效")
 	}
 }
+
+// 创建较大的测试栅格 (33x33 正弦地形)
+func createWaveRaster() *RasterDouble {
+	const size = 33
+	data := make([]float64, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			data[y*size+x] = 10*math.Sin(float64(x)/4) + 8*math.Cos(float64(y)/5)
+		}
+	}
+	raster := NewRasterDoubleWithData(size, size, data)
+	raster.SetXYPos(0, 0, 1.0)
+	return raster
+}
+
+// 测试顶点/三角形预算终止模式
+func TestZemlyaMeshBudget(t *testing.T) {
+	full := NewZemlyaMesh(&GeoConfig{})
+	full.LoadRaster(createWaveRaster())
+	full.GreedyInsert(0.1)
+	fullMesh := full.ToMesh()
+
+	if full.VertexCount != len(fullMesh.Vertices) {
+		t.Errorf("顶点计数不一致: 网格=%d, 计数=%d", len(fullMesh.Vertices), full.VertexCount)
+	}
+
+	t.Run("MaxVertices", func(t *testing.T) {
+		z := NewZemlyaMesh(&GeoConfig{})
+		z.LoadRaster(createWaveRaster())
+		z.GreedyInsertWithBudget(0.1, 40, 0)
+		m := z.ToMesh()
+
+		if len(m.Vertices) > 40 {
+			t.Errorf("顶点数超出预算: %d > 40", len(m.Vertices))
+		}
+		if len(m.Vertices) != z.VertexCount {
+			t.Errorf("顶点计数不一致: 网格=%d, 计数=%d", len(m.Vertices), z.VertexCount)
+		}
+		if z.AchievedError <= 0.1 {
+			t.Errorf("40个顶点不应达到误差阈值, 实际误差 %.4f", z.AchievedError)
+		}
+		if !m.CheckTin() {
+			t.Error("预算模式生成的网格不是有效TIN")
+		}
+	})
+
+	t.Run("MaxTriangles", func(t *testing.T) {
+		z := NewZemlyaMesh(&GeoConfig{})
+		z.LoadRaster(createWaveRaster())
+		z.GreedyInsertWithBudget(0.1, 0, 50)
+		m := z.ToMesh()
+
+		if len(m.Faces) > 50 {
+			t.Errorf("三角形数超出预算: %d > 50", len(m.Faces))
+		}
+		if len(m.Faces) >= len(fullMesh.Faces) {
+			t.Errorf("预算未生效: %d >= %d", len(m.Faces), len(fullMesh.Faces))
+		}
+	})
+
+	t.Run("ErrorFirst", func(t *testing.T) {
+		// 预算足够大时由误差阈值先终止
+		z := NewZemlyaMesh(&GeoConfig{})
+		z.LoadRaster(createWaveRaster())
+		z.GreedyInsertWithBudget(0.1, 100000, 100000)
+
+		if z.VertexCount >= 100000 || z.Triangles.Len() >= 100000 {
+			t.Errorf("不应达到预算: 顶点=%d, 三角形=%d", z.VertexCount, z.Triangles.Len())
+		}
+		if z.AchievedError > 1.0 {
+			t.Errorf("实际误差 %.4f 过大", z.AchievedError)
+		}
+	})
+
+	t.Run("MoreVerticesLessError", func(t *testing.T) {
+		small := NewZemlyaMesh(&GeoConfig{})
+		small.LoadRaster(createWaveRaster())
+		small.GreedyInsertWithBudget(0, 20, 0)
+
+		large := NewZemlyaMesh(&GeoConfig{})
+		large.LoadRaster(createWaveRaster())
+		large.GreedyInsertWithBudget(0, 200, 0)
+
+		if large.AchievedError >= small.AchievedError {
+			t.Errorf("更多顶点应得到更小误差: 20顶点=%.4f, 200顶点=%.4f",
+				small.AchievedError, large.AchievedError)
+		}
+	})
+}
+
+// 窄类型 DEM 直接参与 TIN 生成，不复制为双精度栅格
+func TestZemlyaMeshNarrowElevation(t *testing.T) {
+	wave := createWaveRaster()
+	// 以厘米为单位的 int16 DEM
+	short := ConvertRaster(wave, 100, 0, int16(math.MinInt16))
+	short.SetValue(0, 5, short.NoData)
+
+	narrow := NewZemlyaMesh(&GeoConfig{})
+	if err := narrow.LoadElevation(NewScaledRaster(short, 0.01, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if narrow.Raster != nil {
+		t.Fatal("窄类型栅格不应复制为双精度")
+	}
+	narrow.GreedyInsert(0.1)
+	if narrow.AchievedError > 0.1+1e-9 {
+		t.Errorf("实际误差 %.6f 超过阈值", narrow.AchievedError)
+	}
+
+	mesh := narrow.ToMesh()
+	if len(mesh.Vertices) < 4 || len(mesh.Faces) == 0 {
+		t.Fatalf("网格为空: %d 顶点", len(mesh.Vertices))
+	}
+	// 高程按比例换算为米，波形地形范围约为 [-18, 18]
+	if mesh.BBox[0][2] < -18 || mesh.BBox[1][2] > 18 || mesh.BBox[1][2]-mesh.BBox[0][2] < 30 {
+		t.Errorf("高程范围错误: %.4f ~ %.4f", mesh.BBox[0][2], mesh.BBox[1][2])
+	}
+
+	// float32 栅格无需比例换算
+	single := NewZemlyaMesh(&GeoConfig{})
+	single.LoadElevation(ConvertRaster(wave, 1, 0, float32(math.NaN())))
+	single.GreedyInsert(0.1)
+	if single.AchievedError > 0.1+1e-5 {
+		t.Errorf("float32 DEM 实际误差 %.6f 超过阈值", single.AchievedError)
+	}
+}
+
+// 非正方形像元：顶点按 X/Y 像元大小放置，三角网在地面坐标下满足 Delaunay 条件
+func TestZemlyaMeshNonSquarePixels(t *testing.T) {
+	raster := createWaveRaster()
+	raster.SetXYPosCellSize(0, 0, 4, 1)
+
+	z := NewZemlyaMesh(&GeoConfig{})
+	z.LoadRaster(raster)
+	z.GreedyInsert(0.1)
+	if z.aspect != 4 {
+		t.Fatalf("地面比例错误: %v", z.aspect)
+	}
+	mesh := z.ToMesh()
+	if mesh.BBox[0][0] != 2 || mesh.BBox[1][0] != 130 || mesh.BBox[0][1] != 0.5 || mesh.BBox[1][1] != 32.5 {
+		t.Fatalf("顶点范围错误: %v", mesh.BBox)
+	}
+
+	for _, f := range mesh.Faces {
+		a, b, c := mesh.Vertices[f[0]], mesh.Vertices[f[1]], mesh.Vertices[f[2]]
+		// 外接圆圆心与半径
+		d := 2 * (a[0]*(b[1]-c[1]) + b[0]*(c[1]-a[1]) + c[0]*(a[1]-b[1]))
+		if d == 0 {
+			continue
+		}
+		a2, b2, c2 := a[0]*a[0]+a[1]*a[1], b[0]*b[0]+b[1]*b[1], c[0]*c[0]+c[1]*c[1]
+		ux := (a2*(b[1]-c[1]) + b2*(c[1]-a[1]) + c2*(a[1]-b[1])) / d
+		uy := (a2*(c[0]-b[0]) + b2*(a[0]-c[0]) + c2*(b[0]-a[0])) / d
+		r := math.Hypot(a[0]-ux, a[1]-uy)
+		for _, v := range mesh.Vertices {
+			if math.Hypot(v[0]-ux, v[1]-uy) < r*(1-1e-6) {
+				t.Fatalf("顶点 %v 位于三角形 %v %v %v 的外接圆内", v, a, b, c)
+			}
+		}
+	}
+
+	// 经纬度栅格按中心纬度换算经度方向长度
+	geographic := createWaveRaster()
+	geographic.SetXYPosCellSize(10, 59.5, 1.0/32, 1.0/32)
+	g := NewZemlyaMesh(&GeoConfig{SrcProj: EPSG4326})
+	g.LoadRaster(geographic)
+	if a := g.groundAspect(); math.Abs(a-0.5) > 1e-3 {
+		t.Errorf("纬度 60 度处比例应约为 0.5: %v", a)
+	}
+}
+
+// 已插入顶点所在单元下标的哈希
+func insertedCellsHash(z *ZemlyaMesh) uint64 {
+	h := fnv.New64a()
+	for i, v := range z.Result.Data {
+		if !math.IsNaN(v) {
+			h.Write([]byte{byte(i), byte(i >> 8), byte(i >> 16)})
+		}
+	}
+	return h.Sum64()
+}
+
+// 固定不设预算时的插入结果，候选队列为按重要性出队的最大堆，
+// 扫描行时跳过已使用单元平面高程仍随列步进
+func TestZemlyaMeshRegression(t *testing.T) {
+	for _, c := range []struct {
+		maxError  float64
+		vertices  int
+		triangles int
+		hash      uint64
+	}{
+		{0.1, 883, 1658, 0x2c60d1208b8b6cb1},
+		{1, 201, 366, 0x55ad3a9b58e6968f},
+	} {
+		z := NewZemlyaMesh(&GeoConfig{})
+		z.LoadRaster(createWaveRaster())
+		z.GreedyInsert(c.maxError)
+		if z.VertexCount != c.vertices || len(triangleList(z)) != c.triangles {
+			t.Errorf("maxError=%v: 顶点/三角形数 %d/%d, 期望 %d/%d",
+				c.maxError, z.VertexCount, len(triangleList(z)), c.vertices, c.triangles)
+		}
+		if h := insertedCellsHash(z); h != c.hash {
+			t.Errorf("maxError=%v: 插入位置哈希 %#x, 期望 %#x", c.maxError, h, c.hash)
+		}
+	}
+}
+
+// 同一网格重复插入应回收上次的边与三角形并得到有效网格
+func TestZemlyaMeshReuse(t *testing.T) {
+	z := NewZemlyaMesh(&GeoConfig{})
+	z.LoadRaster(createWaveRaster())
+	z.GreedyInsert(0.1)
+	first := z.Triangles.Len()
+
+	z.GreedyInsert(0.1)
+	m := z.ToMesh()
+	if !m.CheckTin() {
+		t.Fatal("重复插入生成的网格不是有效TIN")
+	}
+	if z.AchievedError > 0.1+1e-9 {
+		t.Errorf("重复插入误差 %.4f 超过阈值 0.1", z.AchievedError)
+	}
+	if n := z.Triangles.Len(); n > 2*first {
+		t.Errorf("旧三角形未回收: 第一次 %d, 第二次 %d", first, n)
+	}
+	if len(m.Vertices) != z.VertexCount {
+		t.Errorf("顶点计数不一致: 网格=%d, 计数=%d", len(m.Vertices), z.VertexCount)
+	}
+}
+
+func newSampleZemlya(workers int) *ZemlyaMesh {
+	z := NewZemlyaMesh(&GeoConfig{})
+	z.Workers = workers
+	z.LoadRaster(CreateSampleDEM(GenerateSampleTileBBox(16), 16, 180.0))
+	return z
+}
+
+// 按链表顺序列出三角形顶点
+func triangleList(z *ZemlyaMesh) [][3][2]float64 {
+	var tris [][3][2]float64
+	for t := z.firstFace; t != nil; t = t.GetLink() {
+		tris = append(tris, [3][2]float64{t.point1(), t.point2(), t.point3()})
+	}
+	return tris
+}
+
+// 并行重采样与三角形扫描的结果应与顺序执行一致
+func TestZemlyaMeshParallel(t *testing.T) {
+	seq := newSampleZemlya(1)
+	seq.GreedyInsert(0.5)
+	par := newSampleZemlya(8)
+	par.GreedyInsert(0.5)
+
+	for _, c := range []struct {
+		name string
+		a, b []float32
+	}{{"Sample", seq.Sample.Data, par.Sample.Data}, {"Insert", seq.Insert.Data, par.Insert.Data}} {
+		for i := range c.a {
+			if c.a[i] != c.b[i] && !(math.IsNaN(float64(c.a[i])) && math.IsNaN(float64(c.b[i]))) {
+				t.Fatalf("%s 第 %d 个单元不一致: %v != %v", c.name, i, c.a[i], c.b[i])
+			}
+		}
+	}
+
+	// 插入结果与三角形链表应完全相同
+	for i := range seq.Result.Data {
+		a, b := seq.Result.Data[i], par.Result.Data[i]
+		if a != b && !(math.IsNaN(a) && math.IsNaN(b)) {
+			t.Fatalf("Result 第 %d 个单元不一致: %v != %v", i, a, b)
+		}
+	}
+	seqTris, parTris := triangleList(seq), triangleList(par)
+	if len(seqTris) != len(parTris) {
+		t.Fatalf("三角形数量不一致: %d != %d", len(parTris), len(seqTris))
+	}
+	for i := range seqTris {
+		if seqTris[i] != parTris[i] {
+			t.Fatalf("第 %d 个三角形不一致: %v != %v", i, parTris[i], seqTris[i])
+		}
+	}
+	if seq.VertexCount != par.VertexCount || seq.AchievedError != par.AchievedError {
+		t.Errorf("顶点数/误差不一致: %d/%v != %d/%v", par.VertexCount, par.AchievedError, seq.VertexCount, seq.AchievedError)
+	}
+
+	// 同一网格上并行扫描的候选队列与令牌应与顺序扫描相同
+	scan := func(workers int) (PQ, []int32) {
+		seq.Workers = workers
+		seq.Counter = 0
+		seq.Candidates.Clear()
+		seq.Token.Fill(0)
+		seq.scanAllTriangles()
+		return append(PQ(nil), seq.Candidates.Candidates...), append([]int32(nil), seq.Token.Data...)
+	}
+	wantQueue, wantToken := scan(1)
+	gotQueue, gotToken := scan(8)
+	if len(wantQueue) == 0 || len(gotQueue) != len(wantQueue) {
+		t.Fatalf("候选队列长度不一致: %d != %d", len(gotQueue), len(wantQueue))
+	}
+	for i := range wantQueue {
+		w, g := wantQueue[i], gotQueue[i]
+		if w.X != g.X || w.Y != g.Y || w.Token != g.Token || w.Importance != g.Importance || w.Triangle != g.Triangle {
+			t.Fatalf("第 %d 个候选点不一致: %+v != %+v", i, *g, *w)
+		}
+	}
+	for i := range wantToken {
+		if wantToken[i] != gotToken[i] {
+			t.Fatalf("第 %d 个令牌不一致: %d != %d", i, gotToken[i], wantToken[i])
+		}
+	}
+
+	// 大三角形按行块并行扫描时，自定义重要性的选点也应与顺序扫描一致
+	withImportance := func(workers int) *ZemlyaMesh {
+		z := newSampleZemlya(workers)
+		z.Importance = NormalDeviationImportance
+		z.GreedyInsert(0.5)
+		return z
+	}
+	seq, par = withImportance(1), withImportance(8)
+	if insertedCellsHash(seq) != insertedCellsHash(par) || len(triangleList(seq)) != len(triangleList(par)) {
+		t.Error("设置重要性函数时并行结果与顺序结果不一致")
+	}
+
+	// 高程取整后误差大量相同，合并行块时须保留先扫描到的单元
+	quantized := func(workers int) *ZemlyaMesh {
+		dem := CreateSampleDEM(GenerateSampleTileBBox(16), 16, 180.0)
+		for i, v := range dem.Data {
+			dem.Data[i] = math.Round(v / 4)
+		}
+		z := NewZemlyaMesh(&GeoConfig{})
+		z.Workers = workers
+		z.LoadRaster(dem)
+		z.GreedyInsert(0.5)
+		return z
+	}
+	if insertedCellsHash(quantized(1)) != insertedCellsHash(quantized(8)) {
+		t.Error("误差相同时并行结果与顺序结果不一致")
+	}
+}
+
+func BenchmarkGreedyInsert(b *testing.B) {
+	for _, bc := range []struct {
+		name    string
+		workers int
+	}{{"Sequential", 1}, {"Parallel", 0}} {
+		b.Run(bc.name, func(b *testing.B) {
+			dem := CreateSampleDEM(GenerateSampleTileBBox(16), 16, 180.0)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				z := NewZemlyaMesh(&GeoConfig{})
+				z.Workers = bc.workers
+				z.LoadRaster(dem)
+				z.GreedyInsert(0.5)
+			}
+		})
+	}
+
+	// 同一网格重复插入时复用已分配的边与三角形
+	b.Run("Reuse", func(b *testing.B) {
+		z := NewZemlyaMesh(&GeoConfig{})
+		z.LoadRaster(CreateSampleDEM(GenerateSampleTileBBox(16), 16, 180.0))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			z.GreedyInsert(0.5)
+		}
+	})
+}